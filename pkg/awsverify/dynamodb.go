@@ -0,0 +1,397 @@
+package awsverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetDynamoDBTable waits until the table is ACTIVE, and returns its description.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//
+// Returns:
+//   - *types.TableDescription: The description of the table.
+func GetDynamoDBTable(t *testing.T, adapter cloudprovider.AWSAdapter, table string) *types.TableDescription {
+	var description *types.TableDescription
+
+	err := waitUntil("table "+table, func(ctx context.Context) (bool, error) {
+		out, err := adapter.NewDynamoDB().DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &table})
+		if err != nil {
+			return false, err
+		}
+
+		description = out.Table
+
+		return description.TableStatus == types.TableStatusActive, nil
+	}, dynamoDBNotFoundCodes...)
+	require.NoErrorf(t, err, "The table %s is not active", table)
+
+	return description
+}
+
+// AssertDynamoDBTableKeySchema checks the primary key of the table.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - hashKey: The expected partition key.
+//   - rangeKey: The expected sort key, or an empty string if the table has none.
+//
+// Example:
+//
+//	awsverify.AssertDynamoDBTableKeySchema(t, s.GetAWS(), "orders", "customer_id", "order_id")
+func AssertDynamoDBTableKeySchema(t *testing.T, adapter cloudprovider.AWSAdapter, table, hashKey, rangeKey string) {
+	assertKeySchema(t, GetDynamoDBTable(t, adapter, table).KeySchema, hashKey, rangeKey, "the table "+table)
+}
+
+// AssertDynamoDBTableBillingMode checks the billing mode of the table.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - mode: The expected billing mode (types.BillingModePayPerRequest or types.BillingModeProvisioned).
+func AssertDynamoDBTableBillingMode(t *testing.T, adapter cloudprovider.AWSAdapter, table string, mode types.BillingMode) {
+	description := GetDynamoDBTable(t, adapter, table)
+
+	// The summary is only set once the mode has been chosen explicitly; tables are provisioned otherwise.
+	actual := types.BillingModeProvisioned
+	if description.BillingModeSummary != nil && description.BillingModeSummary.BillingMode != "" {
+		actual = description.BillingModeSummary.BillingMode
+	}
+
+	assert.Equalf(t, mode, actual, "Unexpected billing mode for the table %s", table)
+}
+
+// AssertDynamoDBTableGlobalSecondaryIndex checks that the table has the global secondary index, with the given key,
+// and returns it for further assertions (projection, throughput...).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - indexName: The name of the index.
+//   - hashKey: The expected partition key of the index.
+//   - rangeKey: The expected sort key of the index, or an empty string if it has none.
+//
+// Returns:
+//   - *types.GlobalSecondaryIndexDescription: The description of the index.
+func AssertDynamoDBTableGlobalSecondaryIndex(t *testing.T, adapter cloudprovider.AWSAdapter, table, indexName, hashKey, rangeKey string) *types.GlobalSecondaryIndexDescription {
+	description := GetDynamoDBTable(t, adapter, table)
+
+	for i := range description.GlobalSecondaryIndexes {
+		index := &description.GlobalSecondaryIndexes[i]
+		if derefString(index.IndexName) != indexName {
+			continue
+		}
+
+		assertKeySchema(t, index.KeySchema, hashKey, rangeKey, fmt.Sprintf("the index %s of the table %s", indexName, table))
+
+		return index
+	}
+
+	require.Failf(t, "Global secondary index not found", "The table %s has no global secondary index %s", table, indexName)
+
+	return nil
+}
+
+// AssertDynamoDBTableLocalSecondaryIndex checks that the table has the local secondary index, with the given sort
+// key, and returns it for further assertions. The partition key of a local index is always the one of the table.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - indexName: The name of the index.
+//   - rangeKey: The expected sort key of the index.
+//
+// Returns:
+//   - *types.LocalSecondaryIndexDescription: The description of the index.
+func AssertDynamoDBTableLocalSecondaryIndex(t *testing.T, adapter cloudprovider.AWSAdapter, table, indexName, rangeKey string) *types.LocalSecondaryIndexDescription {
+	description := GetDynamoDBTable(t, adapter, table)
+
+	for i := range description.LocalSecondaryIndexes {
+		index := &description.LocalSecondaryIndexes[i]
+		if derefString(index.IndexName) != indexName {
+			continue
+		}
+
+		hashKey, _ := keySchemaNames(description.KeySchema)
+		assertKeySchema(t, index.KeySchema, hashKey, rangeKey, fmt.Sprintf("the index %s of the table %s", indexName, table))
+
+		return index
+	}
+
+	require.Failf(t, "Local secondary index not found", "The table %s has no local secondary index %s", table, indexName)
+
+	return nil
+}
+
+// AssertDynamoDBTableTTL checks that time to live is enabled on the table, with the given attribute. Since
+// enabling time to live can take up to an hour, the ENABLING status is accepted too.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - attribute: The expected time to live attribute.
+func AssertDynamoDBTableTTL(t *testing.T, adapter cloudprovider.AWSAdapter, table, attribute string) {
+	out, err := retry("table "+table, func(ctx context.Context) (*dynamodb.DescribeTimeToLiveOutput, error) {
+		return adapter.NewDynamoDB().DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: &table})
+	}, dynamoDBNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the time to live of the table %s", table)
+	require.NotNilf(t, out.TimeToLiveDescription, "The table %s has no time to live", table)
+
+	ttl := out.TimeToLiveDescription
+	assert.Containsf(t, []types.TimeToLiveStatus{types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling}, ttl.TimeToLiveStatus,
+		"Time to live is not enabled on the table %s", table)
+	assert.Equalf(t, attribute, derefString(ttl.AttributeName), "Unexpected time to live attribute for the table %s", table)
+}
+
+// AssertDynamoDBTablePointInTimeRecoveryEnabled checks that point-in-time recovery is enabled on the table.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+func AssertDynamoDBTablePointInTimeRecoveryEnabled(t *testing.T, adapter cloudprovider.AWSAdapter, table string) {
+	out, err := retry("table "+table, func(ctx context.Context) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+		return adapter.NewDynamoDB().DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{TableName: &table})
+	}, dynamoDBNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the continuous backups of the table %s", table)
+
+	var status types.PointInTimeRecoveryStatus
+	if backups := out.ContinuousBackupsDescription; backups != nil && backups.PointInTimeRecoveryDescription != nil {
+		status = backups.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus
+	}
+
+	assert.Equalf(t, types.PointInTimeRecoveryStatusEnabled, status, "Point-in-time recovery is not enabled on the table %s", table)
+}
+
+// AssertDynamoDBTableStream checks that the stream of the table is enabled, with the given view type.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - viewType: The expected view type (e.g.: types.StreamViewTypeNewAndOldImages).
+func AssertDynamoDBTableStream(t *testing.T, adapter cloudprovider.AWSAdapter, table string, viewType types.StreamViewType) {
+	stream := GetDynamoDBTable(t, adapter, table).StreamSpecification
+	require.Truef(t, stream != nil && derefBool(stream.StreamEnabled), "The stream of the table %s is not enabled", table)
+
+	assert.Equalf(t, viewType, stream.StreamViewType, "Unexpected stream view type for the table %s", table)
+}
+
+// AssertDynamoDBTableRoundTrip puts the item into the table, reads it back and deletes it, to prove that the table
+// is usable. To run it with the IAM role created by the module, pass an adapter built with
+// cloudprovider.WithAssumeRole. The item is only put if no item has its primary key yet, so that existing data is
+// never overwritten (nor deleted): the assertion fails instead. The item read back is compared by value, e.g.
+// the number "1.0" is the number "1" and the order of the sets does not matter.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - table: The name of the table.
+//   - item: The item. It must hold the primary key of the table, with a value that no item of the table uses.
+//
+// Example:
+//
+//	role, err := cloudprovider.NewAWS("us-east-1", cloudprovider.WithAssumeRole(roleARN, "", ""))
+//	require.NoError(t, err)
+//
+//	awsverify.AssertDynamoDBTableRoundTrip(t, role, "orders", map[string]types.AttributeValue{
+//	    "customer_id": &types.AttributeValueMemberS{Value: "tftest"},
+//	    "order_id":    &types.AttributeValueMemberN{Value: "1"},
+//	})
+func AssertDynamoDBTableRoundTrip(t *testing.T, adapter cloudprovider.AWSAdapter, table string, item map[string]types.AttributeValue) {
+	keySchema := GetDynamoDBTable(t, adapter, table).KeySchema
+
+	key, err := keyFromItem(keySchema, item)
+	require.NoErrorf(t, err, "Invalid item for the table %s", table)
+
+	hashKey, _ := keySchemaNames(keySchema)
+	client := adapter.NewDynamoDB()
+
+	_, err = client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:                &table,
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#hash)"),
+		ExpressionAttributeNames: map[string]string{"#hash": hashKey},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	require.Falsef(t, errors.As(err, &conditionFailed),
+		"An item with the key %v already exists in the table %s, use a key that no item uses", normalizeAttributeValues(key), table)
+	require.NoErrorf(t, err, "Failed to put the item into the table %s", table)
+
+	deleted := false
+
+	defer func() {
+		if deleted {
+			return
+		}
+
+		if _, err := client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{TableName: &table, Key: key}); err != nil {
+			t.Logf("Failed to delete the test item from the table %s: %v", table, err)
+		}
+	}()
+
+	got, err := client.GetItem(context.TODO(), &dynamodb.GetItemInput{TableName: &table, Key: key, ConsistentRead: aws.Bool(true)})
+	require.NoErrorf(t, err, "Failed to get the item from the table %s", table)
+	assert.Equalf(t, normalizeAttributeValues(item), normalizeAttributeValues(got.Item), "The item read from the table %s is not the one put", table)
+
+	_, err = client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{TableName: &table, Key: key})
+	require.NoErrorf(t, err, "Failed to delete the item from the table %s", table)
+
+	deleted = true
+
+	got, err = client.GetItem(context.TODO(), &dynamodb.GetItemInput{TableName: &table, Key: key, ConsistentRead: aws.Bool(true)})
+	require.NoErrorf(t, err, "Failed to get the item from the table %s", table)
+	assert.Emptyf(t, got.Item, "The item is still in the table %s after its deletion", table)
+}
+
+// assertKeySchema checks the partition and sort keys of a table or an index.
+func assertKeySchema(t *testing.T, keySchema []types.KeySchemaElement, hashKey, rangeKey, what string) {
+	actualHash, actualRange := keySchemaNames(keySchema)
+
+	assert.Equalf(t, hashKey, actualHash, "Unexpected partition key for %s", what)
+	assert.Equalf(t, rangeKey, actualRange, "Unexpected sort key for %s", what)
+}
+
+// keySchemaNames returns the names of the partition key and of the sort key (empty if there is none).
+func keySchemaNames(keySchema []types.KeySchemaElement) (hashKey, rangeKey string) {
+	for _, element := range keySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			hashKey = derefString(element.AttributeName)
+		case types.KeyTypeRange:
+			rangeKey = derefString(element.AttributeName)
+		}
+	}
+
+	return hashKey, rangeKey
+}
+
+// keyFromItem extracts the primary key of the item.
+//
+// Parameters:
+//   - keySchema: The key schema of the table.
+//   - item: The item.
+//
+// Returns:
+//   - map[string]types.AttributeValue: The primary key of the item.
+//   - error: An error if an attribute of the key is missing from the item.
+func keyFromItem(keySchema []types.KeySchemaElement, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	key := make(map[string]types.AttributeValue, len(keySchema))
+
+	for _, element := range keySchema {
+		name := derefString(element.AttributeName)
+
+		value, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("the item has no %s attribute, which is part of the primary key", name)
+		}
+
+		key[name] = value
+	}
+
+	return key, nil
+}
+
+// dynamoDBNumber is the canonical form of a DynamoDB number, distinct from the strings.
+type dynamoDBNumber string
+
+// normalizeAttributeValues converts the attributes of an item into plain Go values that compare by value: numbers
+// get a canonical form, whatever their representation ("1.0", "1e0" and "1" are 1), and sets are sorted.
+func normalizeAttributeValues(values map[string]types.AttributeValue) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		normalized[name] = normalizeAttributeValue(value)
+	}
+
+	return normalized
+}
+
+// normalizeAttributeValue converts an attribute value into a plain Go value (see normalizeAttributeValues).
+func normalizeAttributeValue(value types.AttributeValue) interface{} {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return normalizeNumber(v.Value)
+	case *types.AttributeValueMemberB:
+		return v.Value
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(v.Value))
+		for _, element := range v.Value {
+			list = append(list, normalizeAttributeValue(element))
+		}
+
+		return list
+	case *types.AttributeValueMemberM:
+		return normalizeAttributeValues(v.Value)
+	case *types.AttributeValueMemberSS:
+		return sortedStrings(v.Value)
+	case *types.AttributeValueMemberNS:
+		numbers := make([]string, 0, len(v.Value))
+		for _, number := range v.Value {
+			numbers = append(numbers, string(normalizeNumber(number)))
+		}
+
+		sort.Strings(numbers)
+
+		set := make([]dynamoDBNumber, 0, len(numbers))
+		for _, number := range numbers {
+			set = append(set, dynamoDBNumber(number))
+		}
+
+		return set
+	case *types.AttributeValueMemberBS:
+		binaries := make([]string, 0, len(v.Value))
+		for _, binary := range v.Value {
+			binaries = append(binaries, string(binary))
+		}
+
+		return sortedStrings(binaries)
+	default:
+		return value
+	}
+}
+
+// normalizeNumber returns the canonical form of a DynamoDB number, or the number as is if it cannot be parsed.
+func normalizeNumber(number string) dynamoDBNumber {
+	// DynamoDB numbers have up to 38 significant digits, which 256 bits hold exactly.
+	f, _, err := big.ParseFloat(number, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return dynamoDBNumber(number)
+	}
+
+	return dynamoDBNumber(f.Text('g', -1))
+}
+
+// sortedStrings returns a sorted copy of the strings.
+func sortedStrings(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+
+	return sorted
+}
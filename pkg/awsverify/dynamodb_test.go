@@ -0,0 +1,76 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKeySchema = []types.KeySchemaElement{
+	{AttributeName: aws.String("order_id"), KeyType: types.KeyTypeRange},
+	{AttributeName: aws.String("customer_id"), KeyType: types.KeyTypeHash},
+}
+
+func TestKeySchemaNames(t *testing.T) {
+	hashKey, rangeKey := keySchemaNames(testKeySchema)
+	assert.Equal(t, "customer_id", hashKey)
+	assert.Equal(t, "order_id", rangeKey)
+
+	hashKey, rangeKey = keySchemaNames(testKeySchema[1:])
+	assert.Equal(t, "customer_id", hashKey)
+	assert.Empty(t, rangeKey)
+}
+
+func TestKeyFromItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"customer_id": &types.AttributeValueMemberS{Value: "tftest"},
+		"order_id":    &types.AttributeValueMemberN{Value: "1"},
+		"total":       &types.AttributeValueMemberN{Value: "42"},
+	}
+
+	key, err := keyFromItem(testKeySchema, item)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"customer_id": &types.AttributeValueMemberS{Value: "tftest"},
+		"order_id":    &types.AttributeValueMemberN{Value: "1"},
+	}, key)
+
+	delete(item, "order_id")
+
+	_, err = keyFromItem(testKeySchema, item)
+	assert.ErrorContains(t, err, "the item has no order_id attribute")
+}
+
+func TestNormalizeAttributeValues(t *testing.T) {
+	put := map[string]types.AttributeValue{
+		"customer_id": &types.AttributeValueMemberS{Value: "tftest"},
+		"order_id":    &types.AttributeValueMemberN{Value: "1.0"},
+		"total":       &types.AttributeValueMemberN{Value: "4.20e1"},
+		"tags":        &types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+		"sizes":       &types.AttributeValueMemberNS{Value: []string{"10", "2.50"}},
+		"address":     &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"zip": &types.AttributeValueMemberN{Value: "08001"}}},
+		"lines":       &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberBOOL{Value: true}, &types.AttributeValueMemberNULL{Value: true}}},
+	}
+
+	// The item as DynamoDB returns it.
+	got := map[string]types.AttributeValue{
+		"customer_id": &types.AttributeValueMemberS{Value: "tftest"},
+		"order_id":    &types.AttributeValueMemberN{Value: "1"},
+		"total":       &types.AttributeValueMemberN{Value: "42"},
+		"tags":        &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"sizes":       &types.AttributeValueMemberNS{Value: []string{"2.5", "10"}},
+		"address":     &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"zip": &types.AttributeValueMemberN{Value: "8001"}}},
+		"lines":       &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberBOOL{Value: true}, &types.AttributeValueMemberNULL{Value: true}}},
+	}
+
+	assert.Equal(t, normalizeAttributeValues(put), normalizeAttributeValues(got))
+
+	got["total"] = &types.AttributeValueMemberN{Value: "42.000000000000000000000000000000000001"}
+	assert.NotEqual(t, normalizeAttributeValues(put), normalizeAttributeValues(got), "The 38 significant digits are compared")
+
+	got["total"] = &types.AttributeValueMemberS{Value: "42"}
+	assert.NotEqual(t, normalizeAttributeValues(put), normalizeAttributeValues(got), "A string is not a number")
+}
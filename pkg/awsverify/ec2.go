@@ -0,0 +1,538 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anyIPv4 and anyIPv6 are the CIDR blocks that match every address.
+const (
+	anyIPv4 = "0.0.0.0/0"
+	anyIPv6 = "::/0"
+)
+
+// SecurityGroupRule is an ingress or egress rule of a security group, as expected by
+// AssertSecurityGroupIngressRule and AssertSecurityGroupEgressRule. Exactly one source (or destination) is set.
+type SecurityGroupRule struct {
+	// Protocol is the name or number of the protocol (e.g.: "tcp", "udp", "icmp", "-1" for all).
+	Protocol string
+	// FromPort and ToPort are the range of ports. They are ignored for the "-1" protocol.
+	FromPort int32
+	ToPort   int32
+	// CIDR is an IPv4 or IPv6 CIDR block.
+	CIDR string
+	// SecurityGroupID is the ID of a security group.
+	SecurityGroupID string
+	// PrefixListID is the ID of a prefix list.
+	PrefixListID string
+}
+
+// String returns the rule in a human-readable form (e.g.: "tcp 443-443 with 10.0.0.0/16").
+func (r SecurityGroupRule) String() string {
+	peer := r.CIDR
+	if r.SecurityGroupID != "" {
+		peer = r.SecurityGroupID
+	} else if r.PrefixListID != "" {
+		peer = r.PrefixListID
+	}
+
+	return fmt.Sprintf("%s %d-%d with %s", normalizeProtocol(r.Protocol), r.FromPort, r.ToPort, peer)
+}
+
+// AssertVPCCIDR checks the primary CIDR block of the VPC.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+//   - cidr: The expected CIDR block (e.g.: "10.0.0.0/16").
+func AssertVPCCIDR(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID, cidr string) {
+	out, err := retry("VPC "+vpcID, func(ctx context.Context) (*ec2.DescribeVpcsOutput, error) {
+		return adapter.NewEC2().DescribeVpcs(ctx, &ec2.DescribeVpcsInput{VpcIds: []string{vpcID}})
+	}, "InvalidVpcID.NotFound")
+	require.NoErrorf(t, err, "Failed to get the VPC %s", vpcID)
+	require.Lenf(t, out.Vpcs, 1, "The VPC %s does not exist", vpcID)
+
+	assert.Equalf(t, cidr, derefString(out.Vpcs[0].CidrBlock), "Unexpected CIDR block for the VPC %s", vpcID)
+}
+
+// GetVPCSubnets returns the subnets of the VPC. It waits until the VPC has subnets, since they may not be listed
+// right after apply.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+//
+// Returns:
+//   - []types.Subnet: The subnets of the VPC.
+func GetVPCSubnets(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID string) []types.Subnet {
+	var subnets []types.Subnet
+
+	err := waitUntil("VPC "+vpcID, func(ctx context.Context) (bool, error) {
+		out, err := adapter.NewEC2().DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{Filters: []types.Filter{filter("vpc-id", vpcID)}})
+		if err != nil {
+			return false, err
+		}
+
+		subnets = out.Subnets
+
+		return len(subnets) > 0, nil
+	})
+	require.NoErrorf(t, err, "The VPC %s has no subnet", vpcID)
+
+	return subnets
+}
+
+// AssertVPCSubnetCIDRs checks that the VPC has exactly the subnets with the given CIDR blocks, in any order.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+//   - cidrs: The expected CIDR blocks of the subnets.
+//
+// Example:
+//
+//	awsverify.AssertVPCSubnetCIDRs(t, s.GetAWS(), vpcID, "10.0.0.0/24", "10.0.1.0/24", "10.0.10.0/24", "10.0.11.0/24")
+func AssertVPCSubnetCIDRs(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID string, cidrs ...string) {
+	subnets := GetVPCSubnets(t, adapter, vpcID)
+
+	actual := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		actual = append(actual, derefString(subnet.CidrBlock))
+	}
+
+	assert.ElementsMatchf(t, cidrs, actual, "Unexpected subnets in the VPC %s", vpcID)
+}
+
+// AssertSubnetsSpreadAcrossAZs checks that the subnets are spread across at least the given number of
+// availability zones.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - subnetIDs: The IDs of the subnets (e.g.: the private subnets of the module).
+//   - minAZs: The minimum number of availability zones.
+func AssertSubnetsSpreadAcrossAZs(t *testing.T, adapter cloudprovider.AWSAdapter, subnetIDs []string, minAZs int) {
+	subnets := getSubnets(t, adapter, subnetIDs)
+
+	zones := map[string]bool{}
+	for _, subnet := range subnets {
+		zones[derefString(subnet.AvailabilityZone)] = true
+	}
+
+	assert.GreaterOrEqualf(t, len(zones), minAZs, "The subnets %v are spread across %d availability zone(s)", subnetIDs, len(zones))
+}
+
+// GetSubnetRouteTable returns the route table of the subnet: the one explicitly associated with it, or the main
+// route table of its VPC. It waits until one of them is listed.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - subnetID: The ID of the subnet.
+//
+// Returns:
+//   - *types.RouteTable: The route table of the subnet.
+func GetSubnetRouteTable(t *testing.T, adapter cloudprovider.AWSAdapter, subnetID string) *types.RouteTable {
+	vpcID := derefString(getSubnets(t, adapter, []string{subnetID})[0].VpcId)
+
+	var routeTable *types.RouteTable
+
+	err := waitUntil("subnet "+subnetID, func(ctx context.Context) (bool, error) {
+		routeTables, err := describeRouteTables(ctx, adapter, filter("association.subnet-id", subnetID))
+		if err != nil {
+			return false, err
+		}
+
+		if len(routeTables) == 0 {
+			// Without an explicit association, the subnet uses the main route table of its VPC.
+			routeTables, err = describeRouteTables(ctx, adapter, filter("vpc-id", vpcID), filter("association.main", "true"))
+			if err != nil {
+				return false, err
+			}
+		}
+
+		routeTable = firstRouteTable(routeTables)
+
+		return routeTable != nil, nil
+	})
+	require.NoErrorf(t, err, "The subnet %s has no route table", subnetID)
+
+	return routeTable
+}
+
+// AssertSubnetRoute checks that the route table of the subnet has an active route to the destination, through the
+// target.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - subnetID: The ID of the subnet.
+//   - destination: The destination CIDR block or prefix list ID (e.g.: "0.0.0.0/0").
+//   - target: The ID of the target, or its prefix to check only its kind (e.g.: "igw-", "nat-", "tgw-", "pcx-").
+//
+// Example:
+//
+//	awsverify.AssertSubnetRoute(t, s.GetAWS(), privateSubnetID, "0.0.0.0/0", "nat-")
+func AssertSubnetRoute(t *testing.T, adapter cloudprovider.AWSAdapter, subnetID, destination, target string) {
+	routeTable := GetSubnetRouteTable(t, adapter, subnetID)
+
+	route := findRoute(routeTable.Routes, destination)
+	if !assert.NotNilf(t, route, "The route table %s of the subnet %s has no route to %s",
+		derefString(routeTable.RouteTableId), subnetID, destination) {
+		return
+	}
+
+	assert.Truef(t, strings.HasPrefix(routeTarget(*route), target), "The route to %s of the subnet %s goes through %s, expected %s",
+		destination, subnetID, routeTarget(*route), target)
+	assert.Equalf(t, types.RouteStateActive, route.State, "The route to %s of the subnet %s is not active", destination, subnetID)
+}
+
+// AssertSubnetPublic checks that the subnet is public: its default route goes through an internet gateway.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - subnetID: The ID of the subnet.
+func AssertSubnetPublic(t *testing.T, adapter cloudprovider.AWSAdapter, subnetID string) {
+	AssertSubnetRoute(t, adapter, subnetID, anyIPv4, "igw-")
+}
+
+// AssertInternetGatewayAttached checks that an internet gateway is attached to the VPC. It waits until the
+// attachment is listed.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+func AssertInternetGatewayAttached(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID string) {
+	err := waitUntil("VPC "+vpcID, func(ctx context.Context) (bool, error) {
+		out, err := adapter.NewEC2().DescribeInternetGateways(ctx, &ec2.DescribeInternetGatewaysInput{
+			Filters: []types.Filter{filter("attachment.vpc-id", vpcID)},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		return hasAttachedInternetGateway(out.InternetGateways, vpcID), nil
+	})
+	assert.NoErrorf(t, err, "No internet gateway is attached to the VPC %s", vpcID)
+}
+
+// AssertNATGatewaysAvailable checks the number of available NAT gateways of the VPC, and returns them for further
+// assertions (subnets, addresses...). It waits until the expected number of NAT gateways is available.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+//   - count: The expected number of available NAT gateways (e.g.: one per availability zone).
+//
+// Returns:
+//   - []types.NatGateway: The available NAT gateways.
+func AssertNATGatewaysAvailable(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID string, count int) []types.NatGateway {
+	var gateways []types.NatGateway
+
+	err := waitUntil("VPC "+vpcID, func(ctx context.Context) (bool, error) {
+		out, err := adapter.NewEC2().DescribeNatGateways(ctx, &ec2.DescribeNatGatewaysInput{
+			Filter: []types.Filter{filter("vpc-id", vpcID), filter("state", string(types.NatGatewayStateAvailable))},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		gateways = out.NatGateways
+
+		return len(gateways) >= count, nil
+	})
+	require.NoErrorf(t, err, "Only %d of the %d NAT gateways expected in the VPC %s are available", len(gateways), count, vpcID)
+
+	assert.Lenf(t, gateways, count, "Unexpected number of available NAT gateways in the VPC %s", vpcID)
+
+	return gateways
+}
+
+// AssertSecurityGroupIngressRule checks that the security group has an ingress rule that covers the expected one.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - groupID: The ID of the security group.
+//   - rule: The expected rule.
+//
+// Example:
+//
+//	awsverify.AssertSecurityGroupIngressRule(t, s.GetAWS(), sgID, awsverify.SecurityGroupRule{
+//	    Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "10.0.0.0/16",
+//	})
+func AssertSecurityGroupIngressRule(t *testing.T, adapter cloudprovider.AWSAdapter, groupID string, rule SecurityGroupRule) {
+	group := getSecurityGroup(t, adapter, groupID)
+
+	assert.Truef(t, permissionsContain(group.IpPermissions, rule), "The security group %s has no ingress rule %s", groupID, rule)
+}
+
+// AssertSecurityGroupEgressRule checks that the security group has an egress rule that covers the expected one.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - groupID: The ID of the security group.
+//   - rule: The expected rule.
+func AssertSecurityGroupEgressRule(t *testing.T, adapter cloudprovider.AWSAdapter, groupID string, rule SecurityGroupRule) {
+	group := getSecurityGroup(t, adapter, groupID)
+
+	assert.Truef(t, permissionsContain(group.IpPermissionsEgress, rule), "The security group %s has no egress rule %s", groupID, rule)
+}
+
+// AssertSecurityGroupNotOpenToWorld checks that no ingress rule of the security group allows traffic from any
+// address (0.0.0.0/0 or ::/0).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - groupID: The ID of the security group.
+func AssertSecurityGroupNotOpenToWorld(t *testing.T, adapter cloudprovider.AWSAdapter, groupID string) {
+	group := getSecurityGroup(t, adapter, groupID)
+
+	for _, permission := range group.IpPermissions {
+		for _, ipRange := range permission.IpRanges {
+			assert.NotEqualf(t, anyIPv4, derefString(ipRange.CidrIp), "The security group %s allows %s from any address",
+				groupID, normalizeProtocol(derefString(permission.IpProtocol)))
+		}
+
+		for _, ipRange := range permission.Ipv6Ranges {
+			assert.NotEqualf(t, anyIPv6, derefString(ipRange.CidrIpv6), "The security group %s allows %s from any address",
+				groupID, normalizeProtocol(derefString(permission.IpProtocol)))
+		}
+	}
+}
+
+// AssertVPCEndpoint checks that the VPC has an available endpoint for the service, of the given type, and returns
+// it for further assertions (subnets, route tables, policy...). It waits until such an endpoint is available,
+// whatever the other endpoints of the service.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - vpcID: The ID of the VPC.
+//   - serviceName: The name of the service (e.g.: "com.amazonaws.us-east-1.s3").
+//   - endpointType: The expected type (types.VpcEndpointTypeGateway or types.VpcEndpointTypeInterface).
+//
+// Returns:
+//   - *types.VpcEndpoint: The endpoint.
+func AssertVPCEndpoint(t *testing.T, adapter cloudprovider.AWSAdapter, vpcID, serviceName string, endpointType types.VpcEndpointType) *types.VpcEndpoint {
+	var (
+		endpoints []types.VpcEndpoint
+		endpoint  *types.VpcEndpoint
+	)
+
+	err := waitUntil("VPC "+vpcID, func(ctx context.Context) (bool, error) {
+		out, err := adapter.NewEC2().DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{filter("vpc-id", vpcID), filter("service-name", serviceName)},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		endpoints = out.VpcEndpoints
+		endpoint = findAvailableVPCEndpoint(endpoints, endpointType)
+
+		return endpoint != nil, nil
+	})
+	require.NoErrorf(t, err, "The VPC %s has no available %s endpoint for %s, found: %s",
+		vpcID, endpointType, serviceName, describeVPCEndpoints(endpoints))
+
+	return endpoint
+}
+
+// filter returns an EC2 filter.
+func filter(name string, values ...string) types.Filter {
+	return types.Filter{Name: &name, Values: values}
+}
+
+// getSubnets returns the subnets.
+func getSubnets(t *testing.T, adapter cloudprovider.AWSAdapter, subnetIDs []string) []types.Subnet {
+	out, err := retry(fmt.Sprintf("subnets %v", subnetIDs), func(ctx context.Context) (*ec2.DescribeSubnetsOutput, error) {
+		return adapter.NewEC2().DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: subnetIDs})
+	}, "InvalidSubnetID.NotFound")
+	require.NoErrorf(t, err, "Failed to get the subnets %v", subnetIDs)
+	require.NotEmptyf(t, out.Subnets, "The subnets %v do not exist", subnetIDs)
+
+	return out.Subnets
+}
+
+// describeRouteTables returns the route tables that match the filters.
+func describeRouteTables(ctx context.Context, adapter cloudprovider.AWSAdapter, filters ...types.Filter) ([]types.RouteTable, error) {
+	out, err := adapter.NewEC2().DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{Filters: filters})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.RouteTables, nil
+}
+
+// firstRouteTable returns the first route table, or nil if there is none.
+func firstRouteTable(routeTables []types.RouteTable) *types.RouteTable {
+	if len(routeTables) == 0 {
+		return nil
+	}
+
+	return &routeTables[0]
+}
+
+// hasAttachedInternetGateway reports whether one of the internet gateways is attached to the VPC.
+func hasAttachedInternetGateway(gateways []types.InternetGateway, vpcID string) bool {
+	for _, gateway := range gateways {
+		for _, attachment := range gateway.Attachments {
+			// The attachments of internet gateways are "available", not "attached".
+			if derefString(attachment.VpcId) == vpcID && (attachment.State == "available" || attachment.State == types.AttachmentStatusAttached) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// findAvailableVPCEndpoint returns the first available endpoint of the given type, or nil if there is none.
+func findAvailableVPCEndpoint(endpoints []types.VpcEndpoint, endpointType types.VpcEndpointType) *types.VpcEndpoint {
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+
+		// The API returns the state in lower case, unlike the values of types.State.
+		if endpoint.VpcEndpointType == endpointType && strings.EqualFold(string(types.StateAvailable), string(endpoint.State)) {
+			return endpoint
+		}
+	}
+
+	return nil
+}
+
+// describeVPCEndpoints lists the endpoints with their type and state (e.g.: "vpce-0123 (Interface, pending)").
+func describeVPCEndpoints(endpoints []types.VpcEndpoint) string {
+	if len(endpoints) == 0 {
+		return "none"
+	}
+
+	descriptions := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s, %s)", derefString(endpoint.VpcEndpointId), endpoint.VpcEndpointType, endpoint.State))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// getSecurityGroup returns the security group.
+func getSecurityGroup(t *testing.T, adapter cloudprovider.AWSAdapter, groupID string) *types.SecurityGroup {
+	out, err := retry("security group "+groupID, func(ctx context.Context) (*ec2.DescribeSecurityGroupsOutput, error) {
+		return adapter.NewEC2().DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{groupID}})
+	}, "InvalidGroup.NotFound")
+	require.NoErrorf(t, err, "Failed to get the security group %s", groupID)
+	require.Lenf(t, out.SecurityGroups, 1, "The security group %s does not exist", groupID)
+
+	return &out.SecurityGroups[0]
+}
+
+// findRoute returns the route to the destination CIDR block (IPv4 or IPv6) or prefix list, or nil if there is none.
+func findRoute(routes []types.Route, destination string) *types.Route {
+	for i := range routes {
+		route := &routes[i]
+		if derefString(route.DestinationCidrBlock) == destination ||
+			derefString(route.DestinationIpv6CidrBlock) == destination ||
+			derefString(route.DestinationPrefixListId) == destination {
+			return route
+		}
+	}
+
+	return nil
+}
+
+// routeTarget returns the ID of the target of the route (e.g.: "igw-0123", "nat-0123", "local").
+func routeTarget(route types.Route) string {
+	for _, target := range []*string{
+		route.NatGatewayId, route.TransitGatewayId, route.VpcPeeringConnectionId, route.EgressOnlyInternetGatewayId,
+		route.NetworkInterfaceId, route.InstanceId, route.CarrierGatewayId, route.LocalGatewayId, route.CoreNetworkArn,
+		route.GatewayId,
+	} {
+		if id := derefString(target); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// permissionsContain reports whether one of the permissions covers the rule: same protocol, a port range that
+// includes the rule's one, and the same source or destination.
+func permissionsContain(permissions []types.IpPermission, rule SecurityGroupRule) bool {
+	protocol := normalizeProtocol(rule.Protocol)
+
+	for _, permission := range permissions {
+		permissionProtocol := normalizeProtocol(derefString(permission.IpProtocol))
+		if permissionProtocol != "-1" && permissionProtocol != protocol {
+			continue
+		}
+
+		if permissionProtocol != "-1" && protocol != "-1" && permission.FromPort != nil && permission.ToPort != nil &&
+			(*permission.FromPort > rule.FromPort || *permission.ToPort < rule.ToPort) {
+			continue
+		}
+
+		if permissionHasPeer(permission, rule) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// permissionHasPeer reports whether the permission applies to the source or destination of the rule.
+func permissionHasPeer(permission types.IpPermission, rule SecurityGroupRule) bool {
+	switch {
+	case rule.SecurityGroupID != "":
+		return slices.ContainsFunc(permission.UserIdGroupPairs, func(pair types.UserIdGroupPair) bool {
+			return derefString(pair.GroupId) == rule.SecurityGroupID
+		})
+	case rule.PrefixListID != "":
+		return slices.ContainsFunc(permission.PrefixListIds, func(prefixList types.PrefixListId) bool {
+			return derefString(prefixList.PrefixListId) == rule.PrefixListID
+		})
+	case strings.Contains(rule.CIDR, ":"):
+		return slices.ContainsFunc(permission.Ipv6Ranges, func(ipRange types.Ipv6Range) bool {
+			return derefString(ipRange.CidrIpv6) == rule.CIDR
+		})
+	default:
+		return slices.ContainsFunc(permission.IpRanges, func(ipRange types.IpRange) bool {
+			return derefString(ipRange.CidrIp) == rule.CIDR
+		})
+	}
+}
+
+// normalizeProtocol returns the name EC2 uses for the protocol, given by name or by number.
+func normalizeProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	case "58":
+		return "icmpv6"
+	case "all", "":
+		return "-1"
+	default:
+		return strings.ToLower(protocol)
+	}
+}
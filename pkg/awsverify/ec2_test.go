@@ -0,0 +1,108 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionsContain(t *testing.T) {
+	permissions := []types.IpPermission{
+		{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int32(443),
+			ToPort:     aws.Int32(443),
+			IpRanges:   []types.IpRange{{CidrIp: aws.String("10.0.0.0/16")}},
+			Ipv6Ranges: []types.Ipv6Range{{CidrIpv6: aws.String("2001:db8::/56")}},
+		},
+		{
+			IpProtocol:       aws.String("tcp"),
+			FromPort:         aws.Int32(5432),
+			ToPort:           aws.Int32(5439),
+			UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: aws.String("sg-0123")}},
+		},
+		{
+			IpProtocol:    aws.String("-1"),
+			PrefixListIds: []types.PrefixListId{{PrefixListId: aws.String("pl-0123")}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		rule  SecurityGroupRule
+		found bool
+	}{
+		{name: "ipv4", rule: SecurityGroupRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "10.0.0.0/16"}, found: true},
+		{name: "ipv6", rule: SecurityGroupRule{Protocol: "6", FromPort: 443, ToPort: 443, CIDR: "2001:db8::/56"}, found: true},
+		{name: "security group within the range", rule: SecurityGroupRule{Protocol: "tcp", FromPort: 5432, ToPort: 5432, SecurityGroupID: "sg-0123"}, found: true},
+		{name: "all traffic", rule: SecurityGroupRule{Protocol: "udp", FromPort: 53, ToPort: 53, PrefixListID: "pl-0123"}, found: true},
+		{name: "other port", rule: SecurityGroupRule{Protocol: "tcp", FromPort: 80, ToPort: 80, CIDR: "10.0.0.0/16"}, found: false},
+		{name: "other protocol", rule: SecurityGroupRule{Protocol: "udp", FromPort: 443, ToPort: 443, CIDR: "10.0.0.0/16"}, found: false},
+		{name: "other cidr", rule: SecurityGroupRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "0.0.0.0/0"}, found: false},
+		{name: "range wider than the rule", rule: SecurityGroupRule{Protocol: "tcp", FromPort: 5400, ToPort: 5500, SecurityGroupID: "sg-0123"}, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.found, permissionsContain(permissions, tt.rule), "permissionsContain(%s)", tt.rule)
+		})
+	}
+}
+
+func TestFindRoute(t *testing.T) {
+	routes := []types.Route{
+		{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), State: types.RouteStateActive},
+		{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-0123"), State: types.RouteStateActive},
+		{DestinationIpv6CidrBlock: aws.String("::/0"), EgressOnlyInternetGatewayId: aws.String("eigw-0123")},
+		{DestinationPrefixListId: aws.String("pl-63a5400a"), GatewayId: aws.String("vpce-0123")},
+	}
+
+	assert.Equal(t, "nat-0123", routeTarget(*findRoute(routes, "0.0.0.0/0")))
+	assert.Equal(t, "local", routeTarget(*findRoute(routes, "10.0.0.0/16")))
+	assert.Equal(t, "eigw-0123", routeTarget(*findRoute(routes, "::/0")))
+	assert.Equal(t, "vpce-0123", routeTarget(*findRoute(routes, "pl-63a5400a")))
+	assert.Nil(t, findRoute(routes, "192.168.0.0/16"))
+}
+
+func TestNormalizeProtocol(t *testing.T) {
+	assert.Equal(t, "tcp", normalizeProtocol("6"))
+	assert.Equal(t, "tcp", normalizeProtocol("TCP"))
+	assert.Equal(t, "udp", normalizeProtocol("17"))
+	assert.Equal(t, "-1", normalizeProtocol("all"))
+	assert.Equal(t, "-1", normalizeProtocol("-1"))
+	assert.Equal(t, "tcp 443-443 with sg-0123", SecurityGroupRule{Protocol: "6", FromPort: 443, ToPort: 443, SecurityGroupID: "sg-0123"}.String())
+}
+
+func TestFindAvailableVPCEndpoint(t *testing.T) {
+	endpoints := []types.VpcEndpoint{
+		{VpcEndpointId: aws.String("vpce-gateway"), VpcEndpointType: types.VpcEndpointTypeGateway, State: "available"},
+		{VpcEndpointId: aws.String("vpce-pending"), VpcEndpointType: types.VpcEndpointTypeInterface, State: "pending"},
+		{VpcEndpointId: aws.String("vpce-interface"), VpcEndpointType: types.VpcEndpointTypeInterface, State: "available"},
+	}
+
+	endpoint := findAvailableVPCEndpoint(endpoints, types.VpcEndpointTypeInterface)
+	if assert.NotNil(t, endpoint, "Every endpoint of the service is looked at, not only the first one") {
+		assert.Equal(t, "vpce-interface", *endpoint.VpcEndpointId)
+	}
+
+	assert.Nil(t, findAvailableVPCEndpoint(endpoints[1:2], types.VpcEndpointTypeInterface))
+	assert.Nil(t, findAvailableVPCEndpoint(endpoints, types.VpcEndpointTypeGatewayLoadBalancer))
+
+	assert.Equal(t, "vpce-gateway (Gateway, available), vpce-pending (Interface, pending)", describeVPCEndpoints(endpoints[:2]))
+	assert.Equal(t, "none", describeVPCEndpoints(nil))
+}
+
+func TestHasAttachedInternetGateway(t *testing.T) {
+	gateways := []types.InternetGateway{
+		{Attachments: []types.InternetGatewayAttachment{{VpcId: aws.String("vpc-other"), State: "available"}}},
+		{Attachments: []types.InternetGatewayAttachment{{VpcId: aws.String("vpc-0123"), State: types.AttachmentStatusAttaching}}},
+	}
+
+	assert.False(t, hasAttachedInternetGateway(gateways, "vpc-0123"))
+
+	gateways[1].Attachments[0].State = "available"
+	assert.True(t, hasAttachedInternetGateway(gateways, "vpc-0123"))
+	assert.False(t, hasAttachedInternetGateway(nil, "vpc-0123"))
+}
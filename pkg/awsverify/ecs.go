@@ -0,0 +1,145 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ecsMissingReason is the reason of the failure DescribeServices reports for a service that does not exist.
+const ecsMissingReason = "MISSING"
+
+// WaitForECSServiceSteadyState waits until the service reaches a steady state: a single, completed deployment,
+// with all its desired tasks running. It waits DefaultECSSteadyStateTimeout at most, unless overridden with
+// cloudprovider.WithMaxDuration.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - cluster: The name or ARN of the cluster.
+//   - service: The name or ARN of the service.
+//   - opts: The polling settings.
+//
+// Returns:
+//   - *types.Service: The service, once steady.
+//
+// Example:
+//
+//	svc := awsverify.WaitForECSServiceSteadyState(t, s.GetAWS(), "my-cluster", "api",
+//	    cloudprovider.WithMaxDuration(15*time.Minute))
+func WaitForECSServiceSteadyState(t *testing.T, adapter cloudprovider.AWSAdapter, cluster, service string, opts ...cloudprovider.WaitOptFn) *types.Service {
+	var (
+		current *types.Service
+		state   string
+	)
+
+	err := waitForReadiness("service "+service, DefaultECSSteadyStateTimeout, func(ctx context.Context) (bool, error) {
+		svc, err := describeECSService(ctx, adapter, cluster, service)
+		if err != nil {
+			return false, err
+		}
+
+		if svc == nil {
+			state = "not found"
+			return false, nil
+		}
+
+		current = svc
+
+		var steady bool
+		steady, state, err = ecsServiceSteady(svc)
+
+		return steady, err
+	}, opts, ecsNotFoundCodes...)
+	require.NoErrorf(t, err, "The service %s of the cluster %s did not reach a steady state: %s", service, cluster, state)
+
+	return current
+}
+
+// AssertECSServiceDesiredCount checks the number of tasks the service is asked to run.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - cluster: The name or ARN of the cluster.
+//   - service: The name or ARN of the service.
+//   - count: The expected desired count.
+func AssertECSServiceDesiredCount(t *testing.T, adapter cloudprovider.AWSAdapter, cluster, service string, count int32) {
+	var svc *types.Service
+
+	// A service that is not visible yet is reported as missing, not with an error.
+	err := waitUntil("service "+service, func(ctx context.Context) (bool, error) {
+		var err error
+		svc, err = describeECSService(ctx, adapter, cluster, service)
+
+		return svc != nil, err
+	}, ecsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the service %s of the cluster %s", service, cluster)
+
+	assert.Equalf(t, count, svc.DesiredCount, "Unexpected desired count for the service %s", service)
+}
+
+// describeECSService returns the service, or nil if it is missing. ECS reports the services it cannot describe as
+// failures: a missing one may just not be visible yet, any other failure is turned into an error.
+func describeECSService(ctx context.Context, adapter cloudprovider.AWSAdapter, cluster, service string) (*types.Service, error) {
+	out, err := adapter.NewECS().DescribeServices(ctx, &ecs.DescribeServicesInput{
+		Cluster:  &cluster,
+		Services: []string{service},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Services) == 0 {
+		if len(out.Failures) > 0 && derefString(out.Failures[0].Reason) != ecsMissingReason {
+			return nil, fmt.Errorf("failed to describe the service %s of the cluster %s: %s", service, cluster,
+				derefString(out.Failures[0].Reason))
+		}
+
+		return nil, nil
+	}
+
+	return &out.Services[0], nil
+}
+
+// ecsServiceSteady reports whether the service is in a steady state and, if not, why.
+//
+// Parameters:
+//   - svc: The service.
+//
+// Returns:
+//   - bool: True if the service is steady.
+//   - string: The state of the service (e.g.: "2 deployment(s) in progress").
+//   - error: An error if a deployment of the service failed, so waiting is pointless.
+func ecsServiceSteady(svc *types.Service) (bool, string, error) {
+	for _, deployment := range svc.Deployments {
+		if deployment.RolloutState == types.DeploymentRolloutStateFailed {
+			return false, "deployment failed", fmt.Errorf("the deployment %s failed: %s",
+				derefString(deployment.Id), derefString(deployment.RolloutStateReason))
+		}
+	}
+
+	if status := derefString(svc.Status); status != "ACTIVE" {
+		return false, "status " + status, nil
+	}
+
+	if len(svc.Deployments) != 1 {
+		return false, fmt.Sprintf("%d deployment(s) in progress", len(svc.Deployments)), nil
+	}
+
+	if rollout := svc.Deployments[0].RolloutState; rollout != "" && rollout != types.DeploymentRolloutStateCompleted {
+		return false, "deployment " + string(rollout), nil
+	}
+
+	if svc.RunningCount != svc.DesiredCount || svc.PendingCount != 0 {
+		return false, fmt.Sprintf("%d running, %d pending, %d desired task(s)", svc.RunningCount, svc.PendingCount, svc.DesiredCount), nil
+	}
+
+	return true, "steady", nil
+}
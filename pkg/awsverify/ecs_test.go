@@ -0,0 +1,119 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECSServiceSteady(t *testing.T) {
+	completed := types.Deployment{Id: aws.String("ecs-svc/1"), RolloutState: types.DeploymentRolloutStateCompleted}
+
+	tests := []struct {
+		name    string
+		service types.Service
+		steady  bool
+		state   string
+		err     string
+	}{
+		{
+			name:    "steady",
+			service: types.Service{Status: aws.String("ACTIVE"), Deployments: []types.Deployment{completed}, DesiredCount: 2, RunningCount: 2},
+			steady:  true,
+			state:   "steady",
+		},
+		{
+			name:    "draining",
+			service: types.Service{Status: aws.String("DRAINING")},
+			state:   "status DRAINING",
+		},
+		{
+			name: "rolling out",
+			service: types.Service{Status: aws.String("ACTIVE"), Deployments: []types.Deployment{
+				{Id: aws.String("ecs-svc/2"), RolloutState: types.DeploymentRolloutStateInProgress}, completed,
+			}},
+			state: "2 deployment(s) in progress",
+		},
+		{
+			name:    "tasks starting",
+			service: types.Service{Status: aws.String("ACTIVE"), Deployments: []types.Deployment{completed}, DesiredCount: 2, RunningCount: 1, PendingCount: 1},
+			state:   "1 running, 1 pending, 2 desired task(s)",
+		},
+		{
+			name: "failed",
+			service: types.Service{Status: aws.String("ACTIVE"), Deployments: []types.Deployment{{
+				Id:                 aws.String("ecs-svc/2"),
+				RolloutState:       types.DeploymentRolloutStateFailed,
+				RolloutStateReason: aws.String("tasks failed to start"),
+			}}},
+			state: "deployment failed",
+			err:   "the deployment ecs-svc/2 failed: tasks failed to start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steady, state, err := ecsServiceSteady(&tt.service)
+
+			assert.Equal(t, tt.steady, steady)
+			assert.Equal(t, tt.state, state)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// fakeECS answers DescribeServices with the reason of a failure for the first calls, then with a steady service.
+func fakeECS(reason string, failures int) http.HandlerFunc {
+	calls := 0
+
+	return func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		if calls <= failures {
+			_, _ = fmt.Fprintf(w, `{"services":[],"failures":[{"arn":"arn:aws:ecs:us-east-1:111111111111:service/my-cluster/api","reason":%q}]}`, reason)
+			return
+		}
+
+		_, _ = fmt.Fprint(w, `{"services":[{"serviceName":"api","status":"ACTIVE","desiredCount":2,"runningCount":2,"pendingCount":0,`+
+			`"deployments":[{"id":"ecs-svc/1","rolloutState":"COMPLETED"}]}],"failures":[]}`)
+	}
+}
+
+func TestDescribeECSService(t *testing.T) {
+	svc, err := describeECSService(context.Background(), newTestAdapter(t, fakeECS("MISSING", 1)), "my-cluster", "api")
+	require.NoError(t, err)
+	assert.Nil(t, svc, "A missing service is not an error, it may not be visible yet")
+
+	_, err = describeECSService(context.Background(), newTestAdapter(t, fakeECS("ACCESS_DENIED", 1)), "my-cluster", "api")
+	assert.ErrorContains(t, err, "ACCESS_DENIED")
+
+	svc, err = describeECSService(context.Background(), newTestAdapter(t, fakeECS("MISSING", 0)), "my-cluster", "api")
+	require.NoError(t, err)
+	require.NotNil(t, svc)
+	assert.Equal(t, "api", aws.ToString(svc.ServiceName))
+}
+
+func TestWaitForECSServiceSteadyStateWhileMissing(t *testing.T) {
+	adapter := newTestAdapter(t, fakeECS("MISSING", 2))
+
+	svc := WaitForECSServiceSteadyState(t, adapter, "my-cluster", "api",
+		cloudprovider.WithInitialInterval(10*time.Millisecond), cloudprovider.WithMaxDuration(10*time.Second))
+	assert.Equal(t, int32(2), svc.RunningCount)
+
+	AssertECSServiceDesiredCount(t, newTestAdapter(t, fakeECS("MISSING", 1)), "my-cluster", "api", 2)
+}
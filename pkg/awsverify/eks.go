@@ -0,0 +1,184 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// WaitForEKSClusterActive waits until the cluster is ACTIVE, and all its managed node groups are ACTIVE too. It
+// waits DefaultEKSActiveTimeout at most, unless overridden with cloudprovider.WithMaxDuration.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - cluster: The name of the cluster.
+//   - opts: The polling settings.
+//
+// Returns:
+//   - *types.Cluster: The cluster, once active.
+func WaitForEKSClusterActive(t *testing.T, adapter cloudprovider.AWSAdapter, cluster string, opts ...cloudprovider.WaitOptFn) *types.Cluster {
+	var (
+		current *types.Cluster
+		state   string
+	)
+
+	client := adapter.NewEKS()
+
+	err := waitForReadiness("cluster "+cluster, DefaultEKSActiveTimeout, func(ctx context.Context) (bool, error) {
+		out, err := client.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &cluster})
+		if err != nil {
+			return false, err
+		}
+
+		current = out.Cluster
+
+		switch current.Status {
+		case types.ClusterStatusActive:
+		case types.ClusterStatusFailed, types.ClusterStatusDeleting:
+			return false, fmt.Errorf("the cluster %s is %s", cluster, current.Status)
+		default:
+			state = "cluster " + string(current.Status)
+			return false, nil
+		}
+
+		nodegroups, err := describeEKSNodegroups(ctx, client, cluster)
+		if err != nil {
+			return false, err
+		}
+
+		var ready bool
+		ready, state, err = eksNodegroupsReady(nodegroups)
+
+		return ready, err
+	}, opts, eksNotFoundCodes...)
+	require.NoErrorf(t, err, "The cluster %s is not active: %s", cluster, state)
+
+	return current
+}
+
+// AssertEKSClusterVersion checks the Kubernetes version of the cluster.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - cluster: The name of the cluster.
+//   - version: The expected Kubernetes version (e.g.: "1.29").
+func AssertEKSClusterVersion(t *testing.T, adapter cloudprovider.AWSAdapter, cluster, version string) {
+	actual := derefString(getEKSCluster(t, adapter, cluster).Version)
+
+	assert.Truef(t, matchesVersion(actual, version), "The cluster %s runs Kubernetes %s, expected %s", cluster, actual, version)
+}
+
+// AssertEKSClusterSecretsEncrypted checks that the Kubernetes secrets of the cluster are encrypted with a KMS key.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - cluster: The name of the cluster.
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS). If empty, the key is not checked.
+func AssertEKSClusterSecretsEncrypted(t *testing.T, adapter cloudprovider.AWSAdapter, cluster, kmsKeyID string) {
+	for _, config := range getEKSCluster(t, adapter, cluster).EncryptionConfig {
+		if !slices.Contains(config.Resources, "secrets") {
+			continue
+		}
+
+		if kmsKeyID == "" {
+			return
+		}
+
+		var keyARN string
+		if config.Provider != nil {
+			keyARN = derefString(config.Provider.KeyArn)
+		}
+
+		if !assert.NotEmptyf(t, keyARN, "The secrets of the cluster %s have no KMS key, expected %s", cluster, kmsKeyID) {
+			return
+		}
+
+		assertKMSKey(t, adapter, keyARN, kmsKeyID, "the secrets of the cluster "+cluster)
+
+		return
+	}
+
+	assert.Failf(t, "Secrets not encrypted", "The secrets of the cluster %s are not encrypted with a KMS key", cluster)
+}
+
+// getEKSCluster returns the cluster.
+func getEKSCluster(t *testing.T, adapter cloudprovider.AWSAdapter, cluster string) *types.Cluster {
+	out, err := retry("cluster "+cluster, func(ctx context.Context) (*eks.DescribeClusterOutput, error) {
+		return adapter.NewEKS().DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &cluster})
+	}, eksNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the cluster %s", cluster)
+
+	return out.Cluster
+}
+
+// describeEKSNodegroups returns the managed node groups of the cluster.
+func describeEKSNodegroups(ctx context.Context, client *eks.Client, cluster string) ([]types.Nodegroup, error) {
+	var nodegroups []types.Nodegroup
+
+	paginator := eks.NewListNodegroupsPaginator(client, &eks.ListNodegroupsInput{ClusterName: &cluster})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range page.Nodegroups {
+			out, err := client.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{ClusterName: &cluster, NodegroupName: &name})
+			if err != nil {
+				return nil, err
+			}
+
+			nodegroups = append(nodegroups, *out.Nodegroup)
+		}
+	}
+
+	return nodegroups, nil
+}
+
+// eksNodegroupsReady reports whether all the node groups are active and, if not, which ones are not.
+//
+// Parameters:
+//   - nodegroups: The node groups.
+//
+// Returns:
+//   - bool: True if all the node groups are active.
+//   - string: The state of the node groups (e.g.: "node group(s) not active: workers (CREATING)").
+//   - error: An error if a node group failed, so waiting is pointless.
+func eksNodegroupsReady(nodegroups []types.Nodegroup) (bool, string, error) {
+	var pending []string
+
+	for _, nodegroup := range nodegroups {
+		name := derefString(nodegroup.NodegroupName)
+
+		switch nodegroup.Status {
+		case types.NodegroupStatusActive:
+		case types.NodegroupStatusCreateFailed, types.NodegroupStatusDegraded, types.NodegroupStatusDeleteFailed:
+			return false, "node group failed", fmt.Errorf("the node group %s is %s", name, nodegroup.Status)
+		default:
+			pending = append(pending, fmt.Sprintf("%s (%s)", name, nodegroup.Status))
+		}
+	}
+
+	if len(pending) > 0 {
+		return false, "node group(s) not active: " + strings.Join(pending, ", "), nil
+	}
+
+	return true, "active", nil
+}
+
+// matchesVersion reports whether the actual version is the expected one, or a more precise version of it (e.g.:
+// "15.4" matches "15").
+func matchesVersion(actual, expected string) bool {
+	return actual == expected || strings.HasPrefix(actual, expected+".")
+}
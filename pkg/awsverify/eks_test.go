@@ -0,0 +1,33 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEKSNodegroupsReady(t *testing.T) {
+	ready, state, err := eksNodegroupsReady([]types.Nodegroup{
+		{NodegroupName: aws.String("system"), Status: types.NodegroupStatusActive},
+		{NodegroupName: aws.String("workers"), Status: types.NodegroupStatusCreating},
+	})
+	assert.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "node group(s) not active: workers (CREATING)", state)
+
+	ready, _, err = eksNodegroupsReady([]types.Nodegroup{{NodegroupName: aws.String("system"), Status: types.NodegroupStatusActive}})
+	assert.NoError(t, err)
+	assert.True(t, ready)
+
+	_, _, err = eksNodegroupsReady([]types.Nodegroup{{NodegroupName: aws.String("workers"), Status: types.NodegroupStatusCreateFailed}})
+	assert.EqualError(t, err, "the node group workers is CREATE_FAILED")
+}
+
+func TestMatchesVersion(t *testing.T) {
+	assert.True(t, matchesVersion("1.29", "1.29"))
+	assert.True(t, matchesVersion("15.4", "15"))
+	assert.False(t, matchesVersion("1.290", "1.29"))
+	assert.False(t, matchesVersion("14.9", "15"))
+}
@@ -0,0 +1,285 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetIAMRoleTrustPolicy returns the parsed trust policy (assume role policy) of the role.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//
+// Returns:
+//   - *PolicyDocument: The trust policy.
+func GetIAMRoleTrustPolicy(t *testing.T, adapter cloudprovider.AWSAdapter, roleName string) *PolicyDocument {
+	out, err := retry("role "+roleName, func(ctx context.Context) (*iam.GetRoleOutput, error) {
+		return adapter.NewIAM().GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName})
+	}, iamNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the role %s", roleName)
+
+	policy, err := ParsePolicyDocument(derefString(out.Role.AssumeRolePolicyDocument))
+	require.NoErrorf(t, err, "Failed to parse the trust policy of the role %s", roleName)
+
+	return policy
+}
+
+// AssertIAMRoleTrustsPrincipal checks that the trust policy of the role allows the given principals to assume it.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//   - principalType: The type of the principals (e.g.: "AWS", "Service", "Federated").
+//   - principals: The expected principals (e.g.: "lambda.amazonaws.com", "arn:aws:iam::123456789012:root").
+//
+// Example:
+//
+//	awsverify.AssertIAMRoleTrustsPrincipal(t, s.GetAWS(), "my-role", "Service", "lambda.amazonaws.com")
+func AssertIAMRoleTrustsPrincipal(t *testing.T, adapter cloudprovider.AWSAdapter, roleName, principalType string, principals ...string) {
+	AssertIAMRoleTrustPolicyStatement(t, adapter, roleName, PolicyStatement{
+		Effect:    "Allow",
+		Principal: PolicyPrincipal{principalType: principals},
+	})
+}
+
+// AssertIAMRoleTrustPolicyStatement checks that the trust policy of the role has a statement that contains the
+// expected one (see PolicyStatement.Contains), e.g.: to check the conditions of a federated principal.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//   - expected: The expected statement. Its empty fields are not checked.
+//
+// Example:
+//
+//	awsverify.AssertIAMRoleTrustPolicyStatement(t, s.GetAWS(), "github-actions", awsverify.PolicyStatement{
+//	    Action:    awsverify.StringOrSlice{"sts:AssumeRoleWithWebIdentity"},
+//	    Condition: map[string]map[string]awsverify.StringOrSlice{
+//	        "StringLike": {"token.actions.githubusercontent.com:sub": {"repo:my-org/my-repo:*"}},
+//	    },
+//	})
+func AssertIAMRoleTrustPolicyStatement(t *testing.T, adapter cloudprovider.AWSAdapter, roleName string, expected PolicyStatement) {
+	assertPolicyHasStatement(t, GetIAMRoleTrustPolicy(t, adapter, roleName), expected, "the trust policy of the role "+roleName)
+}
+
+// AssertIAMRoleManagedPolicies checks that the managed policies are attached to the role. Other attached policies
+// are ignored.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//   - policies: The expected policies, by ARN or by name.
+//
+// Example:
+//
+//	awsverify.AssertIAMRoleManagedPolicies(t, s.GetAWS(), "my-role",
+//	    "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole", "my-custom-policy")
+func AssertIAMRoleManagedPolicies(t *testing.T, adapter cloudprovider.AWSAdapter, roleName string, policies ...string) {
+	attached, err := retry("role "+roleName, func(ctx context.Context) ([]types.AttachedPolicy, error) {
+		var attached []types.AttachedPolicy
+
+		paginator := iam.NewListAttachedRolePoliciesPaginator(adapter.NewIAM(), &iam.ListAttachedRolePoliciesInput{RoleName: &roleName})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			attached = append(attached, page.AttachedPolicies...)
+		}
+
+		return attached, nil
+	}, iamNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to list the managed policies attached to the role %s", roleName)
+
+	for _, policy := range missingManagedPolicies(attached, policies) {
+		assert.Failf(t, "Managed policy not attached", "The policy %s is not attached to the role %s", policy, roleName)
+	}
+}
+
+// GetIAMRoleInlinePolicy returns the parsed inline policy of the role.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//   - policyName: The name of the inline policy.
+//
+// Returns:
+//   - *PolicyDocument: The inline policy.
+func GetIAMRoleInlinePolicy(t *testing.T, adapter cloudprovider.AWSAdapter, roleName, policyName string) *PolicyDocument {
+	out, err := retry("role "+roleName, func(ctx context.Context) (*iam.GetRolePolicyOutput, error) {
+		return adapter.NewIAM().GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+			RoleName:   &roleName,
+			PolicyName: &policyName,
+		})
+	}, iamNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the inline policy %s of the role %s", policyName, roleName)
+
+	policy, err := ParsePolicyDocument(derefString(out.PolicyDocument))
+	require.NoErrorf(t, err, "Failed to parse the inline policy %s of the role %s", policyName, roleName)
+
+	return policy
+}
+
+// AssertIAMRoleInlinePolicyStatement checks that the inline policy of the role has a statement that contains the
+// expected one (see PolicyStatement.Contains).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - roleName: The name of the role.
+//   - policyName: The name of the inline policy.
+//   - expected: The expected statement. Its empty fields are not checked.
+//
+// Example:
+//
+//	awsverify.AssertIAMRoleInlinePolicyStatement(t, s.GetAWS(), "my-role", "read-bucket", awsverify.PolicyStatement{
+//	    Effect:   "Allow",
+//	    Action:   awsverify.StringOrSlice{"s3:GetObject"},
+//	    Resource: awsverify.StringOrSlice{"arn:aws:s3:::my-bucket/*"},
+//	})
+func AssertIAMRoleInlinePolicyStatement(t *testing.T, adapter cloudprovider.AWSAdapter, roleName, policyName string, expected PolicyStatement) {
+	assertPolicyHasStatement(t, GetIAMRoleInlinePolicy(t, adapter, roleName, policyName), expected,
+		fmt.Sprintf("the inline policy %s of the role %s", policyName, roleName))
+}
+
+// SimulateIAMPrincipalPolicy runs the IAM policy simulator for the principal (user, group or role), with all the
+// policies attached to it, and returns one result per action and resource.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - principalARN: The ARN of the principal.
+//   - actions: The actions to simulate (e.g.: "s3:GetObject").
+//   - resources: The ARNs of the resources. If empty, the actions are simulated on "*".
+//
+// Returns:
+//   - []types.EvaluationResult: The results of the simulation.
+func SimulateIAMPrincipalPolicy(t *testing.T, adapter cloudprovider.AWSAdapter, principalARN string, actions, resources []string) []types.EvaluationResult {
+	require.NotEmptyf(t, actions, "No action to simulate for the principal %s", principalARN)
+
+	results, err := retry("principal "+principalARN, func(ctx context.Context) ([]types.EvaluationResult, error) {
+		var results []types.EvaluationResult
+
+		paginator := iam.NewSimulatePrincipalPolicyPaginator(adapter.NewIAM(), &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: &principalARN,
+			ActionNames:     actions,
+			ResourceArns:    resources,
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, page.EvaluationResults...)
+		}
+
+		return results, nil
+	}, iamNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to simulate the policies of the principal %s", principalARN)
+
+	return results
+}
+
+// AssertIAMActionsAllowed checks, through the IAM policy simulator, that the principal is allowed to run every
+// action on every resource.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - principalARN: The ARN of the principal.
+//   - actions: The actions (e.g.: "s3:GetObject").
+//   - resources: The ARNs of the resources. If empty, the actions are simulated on "*".
+//
+// Example:
+//
+//	awsverify.AssertIAMActionsAllowed(t, s.GetAWS(), roleARN,
+//	    []string{"s3:GetObject"}, []string{"arn:aws:s3:::my-bucket/data.json"})
+func AssertIAMActionsAllowed(t *testing.T, adapter cloudprovider.AWSAdapter, principalARN string, actions, resources []string) {
+	results := SimulateIAMPrincipalPolicy(t, adapter, principalARN, actions, resources)
+
+	if unexpected := unexpectedDecisions(results, true); len(unexpected) > 0 {
+		assert.Failf(t, "Actions not allowed", "The principal %s is not allowed to:\n%s", principalARN, strings.Join(unexpected, "\n"))
+	}
+}
+
+// AssertIAMActionsDenied checks, through the IAM policy simulator, that the principal is denied (explicitly or
+// implicitly) every action on every resource.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - principalARN: The ARN of the principal.
+//   - actions: The actions (e.g.: "s3:DeleteBucket").
+//   - resources: The ARNs of the resources. If empty, the actions are simulated on "*".
+func AssertIAMActionsDenied(t *testing.T, adapter cloudprovider.AWSAdapter, principalARN string, actions, resources []string) {
+	results := SimulateIAMPrincipalPolicy(t, adapter, principalARN, actions, resources)
+
+	if unexpected := unexpectedDecisions(results, false); len(unexpected) > 0 {
+		assert.Failf(t, "Actions not denied", "The principal %s is allowed to:\n%s", principalARN, strings.Join(unexpected, "\n"))
+	}
+}
+
+// missingManagedPolicies returns the expected policies, by ARN or by name, that are not attached.
+func missingManagedPolicies(attached []types.AttachedPolicy, expected []string) []string {
+	var missing []string
+
+	for _, policy := range expected {
+		found := false
+
+		for _, a := range attached {
+			if derefString(a.PolicyArn) == policy || derefString(a.PolicyName) == policy {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, policy)
+		}
+	}
+
+	return missing
+}
+
+// unexpectedDecisions describes the simulation results whose decision is not the expected one.
+//
+// Parameters:
+//   - results: The results of the simulation.
+//   - allowed: True if the actions are expected to be allowed, false if they are expected to be denied.
+//
+// Returns:
+//   - []string: One line per unexpected result (e.g.: "  - s3:GetObject on arn:aws:s3:::my-bucket/*: implicitDeny").
+func unexpectedDecisions(results []types.EvaluationResult, allowed bool) []string {
+	var unexpected []string
+
+	for _, result := range results {
+		if (result.EvalDecision == types.PolicyEvaluationDecisionTypeAllowed) == allowed {
+			continue
+		}
+
+		resource := derefString(result.EvalResourceName)
+		if resource == "" {
+			resource = "*"
+		}
+
+		unexpected = append(unexpected, fmt.Sprintf("  - %s on %s: %s", derefString(result.EvalActionName), resource, result.EvalDecision))
+	}
+
+	return unexpected
+}
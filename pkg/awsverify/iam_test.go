@@ -0,0 +1,56 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingManagedPolicies(t *testing.T) {
+	attached := []types.AttachedPolicy{
+		{
+			PolicyArn:  aws.String("arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"),
+			PolicyName: aws.String("AWSLambdaBasicExecutionRole"),
+		},
+		{
+			PolicyArn:  aws.String("arn:aws:iam::123456789012:policy/my-custom-policy"),
+			PolicyName: aws.String("my-custom-policy"),
+		},
+	}
+
+	assert.Empty(t, missingManagedPolicies(attached, []string{
+		"arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole",
+		"my-custom-policy",
+	}))
+	assert.Equal(t, []string{"ReadOnlyAccess"}, missingManagedPolicies(attached, []string{"ReadOnlyAccess", "my-custom-policy"}))
+}
+
+func TestUnexpectedDecisions(t *testing.T) {
+	results := []types.EvaluationResult{
+		{
+			EvalActionName:   aws.String("s3:GetObject"),
+			EvalResourceName: aws.String("arn:aws:s3:::my-bucket/*"),
+			EvalDecision:     types.PolicyEvaluationDecisionTypeAllowed,
+		},
+		{
+			EvalActionName:   aws.String("s3:DeleteBucket"),
+			EvalResourceName: aws.String("arn:aws:s3:::my-bucket"),
+			EvalDecision:     types.PolicyEvaluationDecisionTypeExplicitDeny,
+		},
+		{
+			EvalActionName: aws.String("iam:CreateUser"),
+			EvalDecision:   types.PolicyEvaluationDecisionTypeImplicitDeny,
+		},
+	}
+
+	assert.Equal(t, []string{
+		"  - s3:DeleteBucket on arn:aws:s3:::my-bucket: explicitDeny",
+		"  - iam:CreateUser on *: implicitDeny",
+	}, unexpectedDecisions(results, true))
+
+	assert.Equal(t, []string{
+		"  - s3:GetObject on arn:aws:s3:::my-bucket/*: allowed",
+	}, unexpectedDecisions(results, false))
+}
@@ -0,0 +1,148 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DecryptWithKMSKey decrypts the ciphertext, and checks that it was encrypted with the given key.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - keyID: The key (ID, alias or ARN).
+//   - ciphertext: The encrypted data.
+//
+// Returns:
+//   - []byte: The decrypted data.
+func DecryptWithKMSKey(t *testing.T, adapter cloudprovider.AWSAdapter, keyID string, ciphertext []byte) []byte {
+	out, err := retry("key "+keyID, func(ctx context.Context) (*kms.DecryptOutput, error) {
+		return adapter.NewKMS().Decrypt(ctx, &kms.DecryptInput{KeyId: &keyID, CiphertextBlob: ciphertext})
+	}, kmsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to decrypt with the key %s", keyID)
+
+	return out.Plaintext
+}
+
+// AssertKMSKeyRoundTrip encrypts a random text with the key, and checks that it decrypts back to the same text.
+// It proves that the caller is allowed to use the key, and that the key is enabled.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - keyID: The key (ID, alias or ARN).
+//
+// Example:
+//
+//	awsverify.AssertKMSKeyRoundTrip(t, s.GetAWS(), "alias/my-key")
+func AssertKMSKeyRoundTrip(t *testing.T, adapter cloudprovider.AWSAdapter, keyID string) {
+	plaintext := []byte("tftest-" + random.UniqueId())
+
+	out, err := retry("key "+keyID, func(ctx context.Context) (*kms.EncryptOutput, error) {
+		return adapter.NewKMS().Encrypt(ctx, &kms.EncryptInput{KeyId: &keyID, Plaintext: plaintext})
+	}, kmsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to encrypt with the key %s", keyID)
+
+	decrypted := DecryptWithKMSKey(t, adapter, keyID, out.CiphertextBlob)
+
+	assert.Equalf(t, string(plaintext), string(decrypted), "The key %s did not decrypt the text it encrypted", keyID)
+}
+
+// AssertKMSKeyEnabled checks that the key is enabled.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - keyID: The key (ID, alias or ARN).
+func AssertKMSKeyEnabled(t *testing.T, adapter cloudprovider.AWSAdapter, keyID string) {
+	key := getKMSKey(t, adapter, keyID)
+
+	assert.Equalf(t, types.KeyStateEnabled, key.KeyState, "The key %s is not enabled", keyID)
+}
+
+// AssertKMSKeyRotationEnabled checks that the automatic rotation of the key is enabled.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - keyID: The key (ID, alias or ARN).
+func AssertKMSKeyRotationEnabled(t *testing.T, adapter cloudprovider.AWSAdapter, keyID string) {
+	// The rotation status is only returned for a key ID or ARN, not for an alias.
+	id := derefString(getKMSKey(t, adapter, keyID).KeyId)
+
+	out, err := retry("key "+keyID, func(ctx context.Context) (*kms.GetKeyRotationStatusOutput, error) {
+		return adapter.NewKMS().GetKeyRotationStatus(ctx, &kms.GetKeyRotationStatusInput{KeyId: &id})
+	}, kmsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the rotation status of the key %s", keyID)
+
+	assert.Truef(t, out.KeyRotationEnabled, "The rotation of the key %s is not enabled", keyID)
+}
+
+// getKMSKey returns the metadata of the key.
+func getKMSKey(t *testing.T, adapter cloudprovider.AWSAdapter, keyID string) *types.KeyMetadata {
+	out, err := retry("key "+keyID, func(ctx context.Context) (*kms.DescribeKeyOutput, error) {
+		return adapter.NewKMS().DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: &keyID})
+	}, kmsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the key %s", keyID)
+
+	return out.KeyMetadata
+}
+
+// assertKMSKey checks that the actual key is the expected one. Both can be given as an ID, an alias or an ARN:
+// unless they match as written, KMS resolves them to the ARN of their key.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - actual: The key the resource is encrypted with.
+//   - expected: The expected key.
+//   - what: The resource, used in the messages (e.g.: "the bucket my-bucket").
+func assertKMSKey(t *testing.T, adapter cloudprovider.AWSAdapter, actual, expected, what string) {
+	same, err := retry("key "+expected, func(ctx context.Context) (bool, error) {
+		return sameKMSKey(ctx, adapter, actual, expected)
+	}, kmsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to resolve the KMS keys %s and %s", actual, expected)
+
+	assert.Truef(t, same, "Unexpected KMS key for %s: %s, expected %s", what, actual, expected)
+}
+
+// sameKMSKey reports whether both keys, given as IDs, aliases or ARNs, are the same key.
+func sameKMSKey(ctx context.Context, adapter cloudprovider.AWSAdapter, actual, expected string) (bool, error) {
+	if matchesKMSKey(actual, expected) {
+		return true, nil
+	}
+
+	actualARN, err := describeKMSKeyARN(ctx, adapter, actual)
+	if err != nil {
+		return false, err
+	}
+
+	expectedARN, err := describeKMSKeyARN(ctx, adapter, expected)
+	if err != nil {
+		return false, err
+	}
+
+	return actualARN == expectedARN, nil
+}
+
+// describeKMSKeyARN returns the ARN of the key, given as an ID, an alias or an ARN.
+func describeKMSKeyARN(ctx context.Context, adapter cloudprovider.AWSAdapter, keyID string) (string, error) {
+	out, err := adapter.NewKMS().DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: &keyID})
+	if err != nil {
+		return "", err
+	}
+
+	if out.KeyMetadata == nil {
+		return "", fmt.Errorf("the key %s has no metadata", keyID)
+	}
+
+	return derefString(out.KeyMetadata.Arn), nil
+}
@@ -0,0 +1,110 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKMSKeyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+// newTestAdapter returns an AWS Cloud Provider (Client) whose clients all target the handler.
+func newTestAdapter(t *testing.T, handler http.HandlerFunc) cloudprovider.AWSAdapter {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	adapter, err := cloudprovider.NewAWS("us-east-1", cloudprovider.WithEndpoint(server.URL))
+	require.NoError(t, err)
+
+	return adapter
+}
+
+// fakeKMS answers DescribeKey with the ARN of the keys, by ID, alias or ARN, and GetKeyRotationStatus with the
+// rotation of the keys, by ID only.
+func fakeKMS(keys map[string]string, rotated ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var input struct{ KeyId string }
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		arn, ok := keys[input.KeyId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"__type":"NotFoundException","message":"Key '%s' does not exist"}`, input.KeyId)
+
+			return
+		}
+
+		keyID := arn[strings.LastIndex(arn, "/")+1:]
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetKeyRotationStatus":
+			if input.KeyId != keyID {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, `{"__type":"ValidationException","message":"Use a key ID or ARN"}`)
+
+				return
+			}
+
+			_, _ = fmt.Fprintf(w, `{"KeyRotationEnabled":%t}`, slices.Contains(rotated, keyID))
+		default:
+			_, _ = fmt.Fprintf(w, `{"KeyMetadata":{"Arn":%q,"KeyId":%q,"KeyState":"Enabled"}}`, arn, keyID)
+		}
+	}
+}
+
+func TestSameKMSKey(t *testing.T) {
+	adapter := newTestAdapter(t, fakeKMS(map[string]string{
+		testKMSKeyARN:   testKMSKeyARN,
+		"alias/my-key":  testKMSKeyARN,
+		"alias/aws/ssm": "arn:aws:kms:us-east-1:123456789012:key/0000aaaa-00aa-00aa-00aa-000000000000",
+	}))
+
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+		same     bool
+	}{
+		{name: "same arn", actual: testKMSKeyARN, expected: testKMSKeyARN, same: true},
+		{name: "id of the arn", actual: testKMSKeyARN, expected: "1234abcd-12ab-34cd-56ef-1234567890ab", same: true},
+		{name: "alias of the key", actual: testKMSKeyARN, expected: "alias/my-key", same: true},
+		{name: "alias returned by the service", actual: "alias/my-key", expected: testKMSKeyARN, same: true},
+		{name: "other key", actual: "alias/aws/ssm", expected: "alias/my-key", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same, err := sameKMSKey(context.Background(), adapter, tt.actual, tt.expected)
+			require.NoError(t, err)
+			assert.Equal(t, tt.same, same)
+		})
+	}
+
+	_, err := sameKMSKey(context.Background(), adapter, testKMSKeyARN, "alias/unknown")
+	assert.ErrorContains(t, err, "NotFoundException")
+}
+
+func TestAssertKMSKeyRotationEnabled(t *testing.T) {
+	adapter := newTestAdapter(t, fakeKMS(map[string]string{
+		"alias/my-key":                         testKMSKeyARN,
+		"1234abcd-12ab-34cd-56ef-1234567890ab": testKMSKeyARN,
+	}, "1234abcd-12ab-34cd-56ef-1234567890ab"))
+
+	// The rotation status is only returned for the key ID, which the alias is resolved to.
+	AssertKMSKeyRotationEnabled(t, adapter, "alias/my-key")
+	AssertKMSKeyEnabled(t, adapter, "alias/my-key")
+}
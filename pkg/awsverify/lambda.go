@@ -0,0 +1,107 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// InvokeLambdaFunction invokes the function synchronously, and checks that it did not fail.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - function: The name, ARN or alias ARN of the function.
+//   - payload: The event. A []byte or a string is sent as is, anything else is encoded to JSON. If nil, no event
+//     is sent.
+//
+// Returns:
+//   - []byte: The response of the function.
+//
+// Example:
+//
+//	response := awsverify.InvokeLambdaFunction(t, s.GetAWS(), "my-function", map[string]string{"name": "tftest"})
+func InvokeLambdaFunction(t *testing.T, adapter cloudprovider.AWSAdapter, function string, payload any) []byte {
+	event, err := lambdaPayload(payload)
+	require.NoErrorf(t, err, "Failed to encode the event of the function %s", function)
+
+	out, err := retry("function "+function, func(ctx context.Context) (*lambda.InvokeOutput, error) {
+		return adapter.NewLambda().Invoke(ctx, &lambda.InvokeInput{
+			FunctionName:   &function,
+			InvocationType: types.InvocationTypeRequestResponse,
+			Payload:        event,
+		})
+	}, lambdaNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to invoke the function %s", function)
+
+	require.Emptyf(t, derefString(out.FunctionError), "The function %s failed: %s", function, string(out.Payload))
+
+	return out.Payload
+}
+
+// AssertLambdaFunctionResponse invokes the function synchronously, and checks its response.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - function: The name, ARN or alias ARN of the function.
+//   - payload: The event (see InvokeLambdaFunction).
+//   - expected: The expected response, in JSON. The formatting and the order of the keys are ignored.
+//
+// Example:
+//
+//	awsverify.AssertLambdaFunctionResponse(t, s.GetAWS(), "my-function", `{"name": "tftest"}`, `{"greeting": "Hello tftest"}`)
+func AssertLambdaFunctionResponse(t *testing.T, adapter cloudprovider.AWSAdapter, function string, payload any, expected string) {
+	response := InvokeLambdaFunction(t, adapter, function, payload)
+
+	assert.JSONEqf(t, expected, string(response), "Unexpected response from the function %s", function)
+}
+
+// AssertLambdaFunctionRuntime checks the runtime of the function.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - function: The name or ARN of the function.
+//   - runtime: The expected runtime (e.g.: types.RuntimePython312).
+func AssertLambdaFunctionRuntime(t *testing.T, adapter cloudprovider.AWSAdapter, function string, runtime types.Runtime) {
+	out, err := retry("function "+function, func(ctx context.Context) (*lambda.GetFunctionConfigurationOutput, error) {
+		return adapter.NewLambda().GetFunctionConfiguration(ctx, &lambda.GetFunctionConfigurationInput{FunctionName: &function})
+	}, lambdaNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the configuration of the function %s", function)
+
+	assert.Equalf(t, runtime, out.Runtime, "Unexpected runtime for the function %s", function)
+}
+
+// lambdaPayload encodes the event of a function.
+//
+// Parameters:
+//   - payload: The event. A []byte or a string is returned as is, anything else is encoded to JSON.
+//
+// Returns:
+//   - []byte: The encoded event, or nil if there is none.
+//   - error: An error if the event could not be encoded to JSON.
+func lambdaPayload(payload any) ([]byte, error) {
+	switch p := payload.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return p, nil
+	case string:
+		return []byte(p), nil
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the event to JSON: %v", err)
+	}
+
+	return encoded, nil
+}
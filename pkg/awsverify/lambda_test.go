@@ -0,0 +1,28 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLambdaPayload(t *testing.T) {
+	payload, err := lambdaPayload(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+
+	payload, err = lambdaPayload(`{"name": "tftest"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "tftest"}`, string(payload))
+
+	payload, err = lambdaPayload([]byte(`[1, 2]`))
+	assert.NoError(t, err)
+	assert.Equal(t, `[1, 2]`, string(payload))
+
+	payload, err = lambdaPayload(map[string]int{"count": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"count":2}`, string(payload))
+
+	_, err = lambdaPayload(make(chan int))
+	assert.ErrorContains(t, err, "failed to encode the event to JSON")
+}
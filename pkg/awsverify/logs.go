@@ -0,0 +1,98 @@
+package awsverify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetLogGroup returns the log group.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name of the log group.
+//
+// Returns:
+//   - *types.LogGroup: The log group.
+func GetLogGroup(t *testing.T, adapter cloudprovider.AWSAdapter, name string) *types.LogGroup {
+	var group *types.LogGroup
+
+	// The log groups are listed by prefix, so a log group that is not visible yet is just missing from the list.
+	err := waitUntil("log group "+name, func(ctx context.Context) (bool, error) {
+		var err error
+		group, err = describeLogGroup(ctx, adapter, name)
+
+		return group != nil, err
+	}, logsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the log group %s", name)
+
+	return group
+}
+
+// AssertLogGroupRetention checks how long the log group keeps its events.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name of the log group.
+//   - days: The expected retention, in days. Zero means the events never expire.
+//
+// Example:
+//
+//	awsverify.AssertLogGroupRetention(t, s.GetAWS(), "/aws/lambda/my-function", 14)
+func AssertLogGroupRetention(t *testing.T, adapter cloudprovider.AWSAdapter, name string, days int32) {
+	group := GetLogGroup(t, adapter, name)
+
+	var actual int32
+	if group.RetentionInDays != nil {
+		actual = *group.RetentionInDays
+	}
+
+	assert.Equalf(t, days, actual, "Unexpected retention (in days) for the log group %s", name)
+}
+
+// AssertLogGroupEncrypted checks that the log group is encrypted with the given KMS key.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name of the log group.
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS). If empty, the key is not checked.
+func AssertLogGroupEncrypted(t *testing.T, adapter cloudprovider.AWSAdapter, name, kmsKeyID string) {
+	keyID := derefString(GetLogGroup(t, adapter, name).KmsKeyId)
+	if !assert.NotEmptyf(t, keyID, "The log group %s is not encrypted with a KMS key", name) {
+		return
+	}
+
+	if kmsKeyID != "" {
+		assertKMSKey(t, adapter, keyID, kmsKeyID, "the log group "+name)
+	}
+}
+
+// describeLogGroup returns the log group, or nil if it is not listed. The log groups are listed by prefix, so the
+// one with the exact name is looked for among them.
+func describeLogGroup(ctx context.Context, adapter cloudprovider.AWSAdapter, name string) (*types.LogGroup, error) {
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(adapter.NewCloudWatchLogs(),
+		&cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: &name})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page.LogGroups {
+			if derefString(page.LogGroups[i].LogGroupName) == name {
+				return &page.LogGroups[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
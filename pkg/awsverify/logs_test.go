@@ -0,0 +1,66 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogs answers DescribeLogGroups with the log groups whose name starts with the prefix, encrypted with
+// testKMSKeyARN, after listing none for the first calls. It lets fakeKMS answer the KMS calls.
+func fakeLogs(hidden int, groups ...string) http.HandlerFunc {
+	kms := fakeKMS(map[string]string{
+		testKMSKeyARN:  testKMSKeyARN,
+		"alias/my-key": testKMSKeyARN,
+	})
+	calls := 0
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("X-Amz-Target"), "TrentService.") {
+			kms(w, r)
+			return
+		}
+
+		calls++
+
+		var input struct{ LogGroupNamePrefix string }
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		var listed []string
+
+		for _, group := range groups {
+			if calls > hidden && strings.HasPrefix(group, input.LogGroupNamePrefix) {
+				listed = append(listed, fmt.Sprintf(`{"logGroupName":%q,"retentionInDays":14,"kmsKeyId":%q}`, group, testKMSKeyARN))
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = fmt.Fprintf(w, `{"logGroups":[%s]}`, strings.Join(listed, ","))
+	}
+}
+
+func TestDescribeLogGroup(t *testing.T) {
+	adapter := newTestAdapter(t, fakeLogs(0, "/aws/lambda/my-function-v2", "/aws/lambda/my-function"))
+
+	group, err := describeLogGroup(context.Background(), adapter, "/aws/lambda/my-function")
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	assert.Equal(t, "/aws/lambda/my-function", *group.LogGroupName, "The log group with the exact name is returned")
+
+	group, err = describeLogGroup(context.Background(), adapter, "/aws/lambda/other")
+	require.NoError(t, err)
+	assert.Nil(t, group, "A log group that is not listed is not an error, it may not be visible yet")
+}
+
+func TestGetLogGroupWhileNotListed(t *testing.T) {
+	adapter := newTestAdapter(t, fakeLogs(1, "/aws/lambda/my-function"))
+
+	AssertLogGroupRetention(t, adapter, "/aws/lambda/my-function", 14)
+	AssertLogGroupEncrypted(t, adapter, "/aws/lambda/my-function", "alias/my-key")
+}
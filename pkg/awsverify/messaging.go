@@ -0,0 +1,413 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMessageIDAttribute is the message attribute that identifies the messages sent by the helpers, so they are
+// told apart from the other messages of the queue.
+const testMessageIDAttribute = "tftest-id"
+
+// fifoSuffix is the suffix of the names of the FIFO topics and queues.
+const fifoSuffix = ".fifo"
+
+// AssertSNSMessageDelivered publishes the message to the topic, and checks that it arrives in the queue subscribed
+// to it, with or without raw message delivery. The message is deleted from the queue once received. The queue
+// should be dedicated to the test: the messages sent by others are left in it, but stay hidden for the visibility
+// timeout of the queue once polled.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - topicARN: The ARN of the topic.
+//   - queueURL: The URL of the queue subscribed to the topic.
+//   - message: The message to publish.
+//
+// Returns:
+//   - *sqstypes.Message: The message received from the queue, for further assertions (attributes...).
+//
+// Example:
+//
+//	awsverify.AssertSNSMessageDelivered(t, s.GetAWS(), topicARN, queueURL, `{"event": "order_created"}`)
+func AssertSNSMessageDelivered(t *testing.T, adapter cloudprovider.AWSAdapter, topicARN, queueURL, message string) *sqstypes.Message {
+	id := publishTestMessages(t, adapter, topicARN, message)
+
+	received := receiveTestMessages(t, adapter, queueURL, id, 1)
+	text, _ := unwrapMessage(received[0])
+	assert.Equalf(t, message, text, "The message received from the queue %s is not the one published to %s", queueURL, topicARN)
+
+	return &received[0]
+}
+
+// AssertSQSMessageRoundTrip sends the message to the queue, and checks that it can be received and deleted. The
+// queue should be dedicated to the test (see AssertSNSMessageDelivered).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - queueURL: The URL of the queue.
+//   - body: The body of the message.
+func AssertSQSMessageRoundTrip(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL, body string) {
+	id := sendTestMessages(t, adapter, queueURL, body)
+
+	received := receiveTestMessages(t, adapter, queueURL, id, 1)
+	text, _ := unwrapMessage(received[0])
+	assert.Equalf(t, body, text, "The message received from the queue %s is not the one sent", queueURL)
+}
+
+// AssertSQSRedrivePolicy checks that the queue sends the messages it fails to process to the dead-letter queue.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - queueURL: The URL of the queue.
+//   - dlqURL: The URL of the dead-letter queue.
+//   - maxReceiveCount: The expected number of receives after which a message is moved to the dead-letter queue.
+func AssertSQSRedrivePolicy(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL, dlqURL string, maxReceiveCount int) {
+	policy := getRedrivePolicy(t, adapter, queueURL)
+
+	assert.Equalf(t, getQueueAttribute(t, adapter, dlqURL, sqstypes.QueueAttributeNameQueueArn), policy.deadLetterTargetARN,
+		"The queue %s does not send its failed messages to %s", queueURL, dlqURL)
+	assert.Equalf(t, maxReceiveCount, policy.maxReceiveCount, "Unexpected maximum receive count for the queue %s", queueURL)
+}
+
+// AssertSQSMessageRedrivenToDLQ sends a message to the queue, receives it as many times as the redrive policy
+// allows without deleting it, and checks that it is then moved to the dead-letter queue. The queues should be
+// dedicated to the test (see AssertSNSMessageDelivered).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - queueURL: The URL of the queue.
+//   - dlqURL: The URL of the dead-letter queue.
+func AssertSQSMessageRedrivenToDLQ(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL, dlqURL string) {
+	policy := getRedrivePolicy(t, adapter, queueURL)
+	client := adapter.NewSQS()
+
+	id := sendTestMessages(t, adapter, queueURL, "tftest redrive")
+
+	receives := 0
+	err := waitUntil("queue "+queueURL, func(ctx context.Context) (bool, error) {
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              &queueURL,
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       1,
+			MessageAttributeNames: []string{testMessageIDAttribute},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, message := range out.Messages {
+			if _, messageID := unwrapMessage(message); messageID != id {
+				continue
+			}
+
+			receives++
+
+			// Makes the message visible again right away, as if its processing had failed.
+			if _, err := client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          &queueURL,
+				ReceiptHandle:     message.ReceiptHandle,
+				VisibilityTimeout: 0,
+			}); err != nil {
+				return false, err
+			}
+		}
+
+		return receives >= policy.maxReceiveCount, nil
+	})
+	require.NoErrorf(t, err, "Failed to receive the test message %d time(s) from the queue %s", policy.maxReceiveCount, queueURL)
+
+	// The message is moved to the dead-letter queue on a receive that follows the last allowed one, so the queue is
+	// received from until the message shows up in the dead-letter queue.
+	err = waitUntil("dead-letter queue "+dlqURL, func(ctx context.Context) (bool, error) {
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              &queueURL,
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       1,
+			MessageAttributeNames: []string{testMessageIDAttribute},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, message := range out.Messages {
+			if _, messageID := unwrapMessage(message); messageID != id {
+				continue
+			}
+
+			if _, err := client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          &queueURL,
+				ReceiptHandle:     message.ReceiptHandle,
+				VisibilityTimeout: 0,
+			}); err != nil {
+				return false, err
+			}
+		}
+
+		dlq, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              &dlqURL,
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       1,
+			MessageAttributeNames: []string{testMessageIDAttribute},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, message := range dlq.Messages {
+			if _, messageID := unwrapMessage(message); messageID != id {
+				continue
+			}
+
+			if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: &dlqURL, ReceiptHandle: message.ReceiptHandle}); err != nil {
+				return false, err
+			}
+
+			return true, nil
+		}
+
+		return false, nil
+	})
+	require.NoErrorf(t, err, "The test message was not moved from the queue %s to the dead-letter queue %s", queueURL, dlqURL)
+}
+
+// AssertSQSFIFOOrdering sends the messages to the FIFO queue, in the same message group, and checks that they are
+// received in the same order.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - queueURL: The URL of the FIFO queue.
+//   - messages: The messages, in the order they are sent.
+func AssertSQSFIFOOrdering(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL string, messages ...string) {
+	require.Truef(t, strings.HasSuffix(queueURL, fifoSuffix), "The queue %s is not a FIFO queue", queueURL)
+
+	id := sendTestMessages(t, adapter, queueURL, messages...)
+
+	assertReceivedInOrder(t, receiveTestMessages(t, adapter, queueURL, id, len(messages)), messages, queueURL)
+}
+
+// AssertSNSFIFOOrdering publishes the messages to the FIFO topic, in the same message group, and checks that they
+// are received in the same order by the FIFO queue subscribed to it.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - topicARN: The ARN of the FIFO topic.
+//   - queueURL: The URL of the FIFO queue subscribed to the topic.
+//   - messages: The messages, in the order they are published.
+func AssertSNSFIFOOrdering(t *testing.T, adapter cloudprovider.AWSAdapter, topicARN, queueURL string, messages ...string) {
+	require.Truef(t, strings.HasSuffix(topicARN, fifoSuffix), "The topic %s is not a FIFO topic", topicARN)
+
+	id := publishTestMessages(t, adapter, topicARN, messages...)
+
+	assertReceivedInOrder(t, receiveTestMessages(t, adapter, queueURL, id, len(messages)), messages, queueURL)
+}
+
+// publishTestMessages publishes the messages to the topic, tagged with a new test ID, which is returned. The
+// messages of a FIFO topic are published in a single message group.
+func publishTestMessages(t *testing.T, adapter cloudprovider.AWSAdapter, topicARN string, messages ...string) string {
+	id := random.UniqueId()
+	client := adapter.NewSNS()
+
+	for i, message := range messages {
+		input := &sns.PublishInput{
+			TopicArn: &topicARN,
+			Message:  aws.String(message),
+			MessageAttributes: map[string]snstypes.MessageAttributeValue{
+				testMessageIDAttribute: {DataType: aws.String("String"), StringValue: aws.String(id)},
+			},
+		}
+
+		if strings.HasSuffix(topicARN, fifoSuffix) {
+			input.MessageGroupId = aws.String(id)
+			input.MessageDeduplicationId = aws.String(fmt.Sprintf("%s-%d", id, i))
+		}
+
+		_, err := retry("topic "+topicARN, func(ctx context.Context) (*sns.PublishOutput, error) {
+			return client.Publish(ctx, input)
+		}, snsNotFoundCodes...)
+		require.NoErrorf(t, err, "Failed to publish to the topic %s", topicARN)
+	}
+
+	return id
+}
+
+// sendTestMessages sends the messages to the queue, tagged with a new test ID, which is returned. The messages of
+// a FIFO queue are sent in a single message group.
+func sendTestMessages(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL string, messages ...string) string {
+	id := random.UniqueId()
+	client := adapter.NewSQS()
+
+	for i, message := range messages {
+		input := &sqs.SendMessageInput{
+			QueueUrl:    &queueURL,
+			MessageBody: aws.String(message),
+			MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+				testMessageIDAttribute: {DataType: aws.String("String"), StringValue: aws.String(id)},
+			},
+		}
+
+		if strings.HasSuffix(queueURL, fifoSuffix) {
+			input.MessageGroupId = aws.String(id)
+			input.MessageDeduplicationId = aws.String(fmt.Sprintf("%s-%d", id, i))
+		}
+
+		_, err := retry("queue "+queueURL, func(ctx context.Context) (*sqs.SendMessageOutput, error) {
+			return client.SendMessage(ctx, input)
+		}, sqsNotFoundCodes...)
+		require.NoErrorf(t, err, "Failed to send a message to the queue %s", queueURL)
+	}
+
+	return id
+}
+
+// receiveTestMessages polls the queue until it receives the given number of messages tagged with the test ID, and
+// deletes them. The messages are returned in the order they are received.
+func receiveTestMessages(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL, id string, count int) []sqstypes.Message {
+	client := adapter.NewSQS()
+
+	var received []sqstypes.Message
+
+	err := waitUntil("queue "+queueURL, func(ctx context.Context) (bool, error) {
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              &queueURL,
+			MaxNumberOfMessages:   10,
+			WaitTimeSeconds:       5,
+			AttributeNames:        []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameAll},
+			MessageAttributeNames: []string{testMessageIDAttribute},
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, message := range out.Messages {
+			if _, messageID := unwrapMessage(message); messageID != id {
+				continue
+			}
+
+			received = append(received, message)
+
+			// Deleting the message releases the next ones of its group, in a FIFO queue.
+			if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{QueueUrl: &queueURL, ReceiptHandle: message.ReceiptHandle}); err != nil {
+				return false, err
+			}
+		}
+
+		return len(received) >= count, nil
+	}, sqsNotFoundCodes...)
+	require.NoErrorf(t, err, "Received %d of the %d test message(s) expected in the queue %s", len(received), count, queueURL)
+
+	return received
+}
+
+// assertReceivedInOrder checks that the texts of the received messages are the expected ones, in the same order.
+func assertReceivedInOrder(t *testing.T, received []sqstypes.Message, expected []string, queueURL string) {
+	texts := make([]string, 0, len(received))
+	for _, message := range received {
+		text, _ := unwrapMessage(message)
+		texts = append(texts, text)
+	}
+
+	assert.Equalf(t, expected, texts, "The messages were not received in order from the queue %s", queueURL)
+}
+
+// snsEnvelope is the JSON document SNS delivers to the queue, unless raw message delivery is enabled.
+type snsEnvelope struct {
+	Type              string `json:"Type"`
+	Message           string `json:"Message"`
+	MessageAttributes map[string]struct {
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// unwrapMessage returns the text of the SQS message and its test ID, whether it was delivered by SNS, with or
+// without raw message delivery, or sent directly to the queue.
+//
+// Parameters:
+//   - message: The SQS message.
+//
+// Returns:
+//   - string: The text of the message.
+//   - string: The test ID of the message, or an empty string if it was not sent by the helpers.
+func unwrapMessage(message sqstypes.Message) (text, id string) {
+	body := derefString(message.Body)
+
+	var envelope snsEnvelope
+	if err := json.Unmarshal([]byte(body), &envelope); err == nil && envelope.Type == "Notification" {
+		return envelope.Message, envelope.MessageAttributes[testMessageIDAttribute].Value
+	}
+
+	if attribute, ok := message.MessageAttributes[testMessageIDAttribute]; ok {
+		id = derefString(attribute.StringValue)
+	}
+
+	return body, id
+}
+
+// redrivePolicy is the redrive policy of a queue.
+type redrivePolicy struct {
+	deadLetterTargetARN string
+	maxReceiveCount     int
+}
+
+// getRedrivePolicy returns the redrive policy of the queue.
+func getRedrivePolicy(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL string) redrivePolicy {
+	attribute := getQueueAttribute(t, adapter, queueURL, sqstypes.QueueAttributeNameRedrivePolicy)
+	require.NotEmptyf(t, attribute, "The queue %s has no redrive policy", queueURL)
+
+	policy, err := parseRedrivePolicy(attribute)
+	require.NoErrorf(t, err, "Failed to parse the redrive policy of the queue %s", queueURL)
+
+	return policy
+}
+
+// getQueueAttribute returns an attribute of the queue, or an empty string if it is not set.
+func getQueueAttribute(t *testing.T, adapter cloudprovider.AWSAdapter, queueURL string, name sqstypes.QueueAttributeName) string {
+	out, err := retry("queue "+queueURL, func(ctx context.Context) (*sqs.GetQueueAttributesOutput, error) {
+		return adapter.NewSQS().GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       &queueURL,
+			AttributeNames: []sqstypes.QueueAttributeName{name},
+		})
+	}, sqsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the attribute %s of the queue %s", name, queueURL)
+
+	return out.Attributes[string(name)]
+}
+
+// parseRedrivePolicy parses the RedrivePolicy attribute of a queue, whose maxReceiveCount is either a number or a
+// string, depending on how the policy was set.
+func parseRedrivePolicy(attribute string) (redrivePolicy, error) {
+	var raw struct {
+		DeadLetterTargetARN string          `json:"deadLetterTargetArn"`
+		MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+	}
+
+	if err := json.Unmarshal([]byte(attribute), &raw); err != nil {
+		return redrivePolicy{}, fmt.Errorf("invalid redrive policy: %v", err)
+	}
+
+	maxReceiveCount, err := strconv.Atoi(strings.Trim(string(raw.MaxReceiveCount), `"`))
+	if err != nil {
+		return redrivePolicy{}, fmt.Errorf("invalid maxReceiveCount in the redrive policy: %v", err)
+	}
+
+	return redrivePolicy{deadLetterTargetARN: raw.DeadLetterTargetARN, maxReceiveCount: maxReceiveCount}, nil
+}
@@ -0,0 +1,56 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnwrapMessage(t *testing.T) {
+	envelope := `{
+  "Type": "Notification",
+  "MessageId": "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+  "TopicArn": "arn:aws:sns:us-east-1:123456789012:orders",
+  "Message": "{\"event\": \"order_created\"}",
+  "MessageAttributes": {"tftest-id": {"Type": "String", "Value": "a1b2c3"}}
+}`
+
+	text, id := unwrapMessage(sqstypes.Message{Body: aws.String(envelope)})
+	assert.Equal(t, `{"event": "order_created"}`, text)
+	assert.Equal(t, "a1b2c3", id)
+
+	// Raw message delivery, or a message sent directly to the queue.
+	text, id = unwrapMessage(sqstypes.Message{
+		Body: aws.String(`{"event": "order_created"}`),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			testMessageIDAttribute: {DataType: aws.String("String"), StringValue: aws.String("a1b2c3")},
+		},
+	})
+	assert.Equal(t, `{"event": "order_created"}`, text)
+	assert.Equal(t, "a1b2c3", id)
+
+	text, id = unwrapMessage(sqstypes.Message{Body: aws.String("hello")})
+	assert.Equal(t, "hello", text)
+	assert.Empty(t, id)
+}
+
+func TestParseRedrivePolicy(t *testing.T) {
+	dlq := "arn:aws:sqs:us-east-1:123456789012:orders-dlq"
+
+	policy, err := parseRedrivePolicy(`{"deadLetterTargetArn":"` + dlq + `","maxReceiveCount":5}`)
+	require.NoError(t, err)
+	assert.Equal(t, redrivePolicy{deadLetterTargetARN: dlq, maxReceiveCount: 5}, policy)
+
+	policy, err = parseRedrivePolicy(`{"deadLetterTargetArn":"` + dlq + `","maxReceiveCount":"3"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 3, policy.maxReceiveCount)
+
+	_, err = parseRedrivePolicy(`{"deadLetterTargetArn":"` + dlq + `"}`)
+	assert.ErrorContains(t, err, "invalid maxReceiveCount")
+
+	_, err = parseRedrivePolicy(`not json`)
+	assert.ErrorContains(t, err, "invalid redrive policy")
+}
@@ -0,0 +1,235 @@
+package awsverify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// StringOrSlice is a policy element that AWS accepts either as a single string or as a list of strings
+// (e.g.: "Action": "s3:GetObject" or "Action": ["s3:GetObject", "s3:PutObject"]).
+type StringOrSlice []string
+
+// UnmarshalJSON decodes either a string or a list of strings.
+func (s *StringOrSlice) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = StringOrSlice{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or a list of strings: %v", err)
+	}
+
+	*s = list
+
+	return nil
+}
+
+// PolicyPrincipal maps the type of a principal (e.g.: "AWS", "Service", "Federated") to its identifiers.
+// The "*" principal is decoded as {"AWS": ["*"]}.
+type PolicyPrincipal map[string]StringOrSlice
+
+// UnmarshalJSON decodes either the "*" principal or a map of principals.
+func (p *PolicyPrincipal) UnmarshalJSON(data []byte) error {
+	var wildcard string
+	if err := json.Unmarshal(data, &wildcard); err == nil {
+		*p = PolicyPrincipal{"AWS": {wildcard}}
+		return nil
+	}
+
+	var principals map[string]StringOrSlice
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return fmt.Errorf("expected \"*\" or a map of principals: %v", err)
+	}
+
+	*p = principals
+
+	return nil
+}
+
+// PolicyStatement is a statement of an IAM policy document (identity-based, resource-based or trust policy).
+type PolicyStatement struct {
+	Sid          string                              `json:"Sid,omitempty"`
+	Effect       string                              `json:"Effect,omitempty"`
+	Principal    PolicyPrincipal                     `json:"Principal,omitempty"`
+	NotPrincipal PolicyPrincipal                     `json:"NotPrincipal,omitempty"`
+	Action       StringOrSlice                       `json:"Action,omitempty"`
+	NotAction    StringOrSlice                       `json:"NotAction,omitempty"`
+	Resource     StringOrSlice                       `json:"Resource,omitempty"`
+	NotResource  StringOrSlice                       `json:"NotResource,omitempty"`
+	Condition    map[string]map[string]StringOrSlice `json:"Condition,omitempty"`
+}
+
+// PolicyDocument is an IAM policy document.
+type PolicyDocument struct {
+	Version   string            `json:"Version,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// UnmarshalJSON decodes a policy document whose Statement is either a single statement or a list of statements.
+func (d *PolicyDocument) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Version   string          `json:"Version"`
+		Statement json.RawMessage `json:"Statement"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d.Version = raw.Version
+	d.Statement = nil
+
+	if len(raw.Statement) == 0 {
+		return nil
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(raw.Statement)), "{") {
+		var statement PolicyStatement
+		if err := json.Unmarshal(raw.Statement, &statement); err != nil {
+			return err
+		}
+
+		d.Statement = []PolicyStatement{statement}
+
+		return nil
+	}
+
+	return json.Unmarshal(raw.Statement, &d.Statement)
+}
+
+// ParsePolicyDocument parses an IAM policy document. IAM returns URL-encoded documents, which are decoded first.
+//
+// Parameters:
+//   - document: The policy document, as JSON, URL-encoded or not.
+//
+// Returns:
+//   - *PolicyDocument: The parsed policy document.
+//   - error: An error if the document is not a valid policy document.
+//
+// Example:
+//
+//	policy, err := ParsePolicyDocument(aws.ToString(role.AssumeRolePolicyDocument))
+//	if err != nil {
+//	    log.Fatalf("Error parsing the trust policy: %v", err)
+//	}
+func ParsePolicyDocument(document string) (*PolicyDocument, error) {
+	if !strings.HasPrefix(strings.TrimSpace(document), "{") {
+		// IAM escapes the documents as URL paths: '+' is a literal plus sign, not a space.
+		decoded, err := url.PathUnescape(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the policy document: %v", err)
+		}
+
+		document = decoded
+	}
+
+	var policy PolicyDocument
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse the policy document: %v", err)
+	}
+
+	return &policy, nil
+}
+
+// FindStatement returns the first statement that contains the expected one (see PolicyStatement.Contains).
+//
+// Parameters:
+//   - expected: The expected statement. Its empty fields are not checked.
+//
+// Returns:
+//   - *PolicyStatement: The matching statement, or nil if there is none.
+func (d *PolicyDocument) FindStatement(expected PolicyStatement) *PolicyStatement {
+	for i := range d.Statement {
+		if d.Statement[i].Contains(expected) {
+			return &d.Statement[i]
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the statement grants (or denies) at least what the expected statement describes:
+// the same Sid and Effect when they are set, and every expected principal, action, resource and condition value.
+// Action names are compared case-insensitively, as IAM does; wildcards are compared literally.
+//
+// Parameters:
+//   - expected: The expected statement. Its empty fields are not checked.
+//
+// Returns:
+//   - bool: True if the statement contains the expected one.
+func (s PolicyStatement) Contains(expected PolicyStatement) bool {
+	if expected.Sid != "" && expected.Sid != s.Sid {
+		return false
+	}
+
+	if expected.Effect != "" && !strings.EqualFold(expected.Effect, s.Effect) {
+		return false
+	}
+
+	return containsAll(s.Action, expected.Action, strings.EqualFold) &&
+		containsAll(s.NotAction, expected.NotAction, strings.EqualFold) &&
+		containsAll(s.Resource, expected.Resource, equal) &&
+		containsAll(s.NotResource, expected.NotResource, equal) &&
+		containsPrincipals(s.Principal, expected.Principal) &&
+		containsPrincipals(s.NotPrincipal, expected.NotPrincipal) &&
+		containsConditions(s.Condition, expected.Condition)
+}
+
+// String returns the statement as compact JSON.
+func (s PolicyStatement) String() string {
+	// A statement is made of strings, slices and maps only, so it always marshals.
+	out, _ := json.Marshal(s)
+
+	return string(out)
+}
+
+func equal(a, b string) bool {
+	return a == b
+}
+
+// containsAll reports whether every expected value is in the actual values.
+func containsAll(actual, expected []string, eq func(a, b string) bool) bool {
+	for _, e := range expected {
+		found := false
+
+		for _, a := range actual {
+			if eq(a, e) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsPrincipals(actual, expected PolicyPrincipal) bool {
+	for principalType, identifiers := range expected {
+		if !containsAll(actual[principalType], identifiers, equal) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsConditions(actual, expected map[string]map[string]StringOrSlice) bool {
+	for operator, keys := range expected {
+		for key, values := range keys {
+			if !containsAll(actual[operator][key], values, equal) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package awsverify
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBucketPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "DenyInsecureTransport",
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": "s3:*",
+      "Resource": ["arn:aws:s3:::my-bucket", "arn:aws:s3:::my-bucket/*"],
+      "Condition": {"Bool": {"aws:SecureTransport": "false"}}
+    },
+    {
+      "Effect": "Allow",
+      "Principal": {"AWS": ["arn:aws:iam::123456789012:root", "arn:aws:iam::210987654321:root"]},
+      "Action": ["s3:GetObject", "s3:ListBucket"],
+      "Resource": "arn:aws:s3:::my-bucket/*"
+    }
+  ]
+}`
+
+func TestParsePolicyDocument(t *testing.T) {
+	policy, err := ParsePolicyDocument(testBucketPolicy)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2012-10-17", policy.Version)
+	require.Len(t, policy.Statement, 2)
+
+	deny := policy.Statement[0]
+	assert.Equal(t, PolicyPrincipal{"AWS": {"*"}}, deny.Principal)
+	assert.Equal(t, StringOrSlice{"s3:*"}, deny.Action)
+	assert.Equal(t, StringOrSlice{"arn:aws:s3:::my-bucket", "arn:aws:s3:::my-bucket/*"}, deny.Resource)
+	assert.Equal(t, StringOrSlice{"false"}, deny.Condition["Bool"]["aws:SecureTransport"])
+
+	allow := policy.Statement[1]
+	assert.Equal(t, StringOrSlice{"arn:aws:iam::123456789012:root", "arn:aws:iam::210987654321:root"}, allow.Principal["AWS"])
+	assert.Equal(t, StringOrSlice{"arn:aws:s3:::my-bucket/*"}, allow.Resource)
+}
+
+func TestParsePolicyDocumentSingleStatement(t *testing.T) {
+	document := `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}}`
+
+	policy, err := ParsePolicyDocument(document)
+	require.NoError(t, err)
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, StringOrSlice{"lambda.amazonaws.com"}, policy.Statement[0].Principal["Service"])
+
+	// IAM returns the documents URL-encoded.
+	policy, err = ParsePolicyDocument(url.QueryEscape(document))
+	require.NoError(t, err)
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, StringOrSlice{"sts:AssumeRole"}, policy.Statement[0].Action)
+
+	// A '+' in a condition value is kept as is.
+	document = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sns:Publish","Condition":{"StringEquals":{"aws:PrincipalTag/team":"a+b c"}}}]}`
+
+	policy, err = ParsePolicyDocument(url.PathEscape(document))
+	require.NoError(t, err)
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, StringOrSlice{"a+b c"}, policy.Statement[0].Condition["StringEquals"]["aws:PrincipalTag/team"])
+
+	_, err = ParsePolicyDocument(`{"Statement": [{"Action": 42}]}`)
+	assert.ErrorContains(t, err, "failed to parse the policy document")
+}
+
+func TestPolicyStatementContains(t *testing.T) {
+	policy, err := ParsePolicyDocument(testBucketPolicy)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected PolicyStatement
+		found    bool
+	}{
+		{
+			name:     "sid and effect",
+			expected: PolicyStatement{Sid: "DenyInsecureTransport", Effect: "Deny"},
+			found:    true,
+		},
+		{
+			name: "condition",
+			expected: PolicyStatement{
+				Effect:    "deny",
+				Action:    StringOrSlice{"s3:*"},
+				Condition: map[string]map[string]StringOrSlice{"Bool": {"aws:SecureTransport": {"false"}}},
+			},
+			found: true,
+		},
+		{
+			name: "subset of principals and actions, case-insensitive actions",
+			expected: PolicyStatement{
+				Effect:    "Allow",
+				Principal: PolicyPrincipal{"AWS": {"arn:aws:iam::210987654321:root"}},
+				Action:    StringOrSlice{"s3:getobject"},
+			},
+			found: true,
+		},
+		{
+			name:     "wrong effect",
+			expected: PolicyStatement{Effect: "Allow", Action: StringOrSlice{"s3:*"}},
+			found:    false,
+		},
+		{
+			name:     "missing action",
+			expected: PolicyStatement{Effect: "Allow", Action: StringOrSlice{"s3:GetObject", "s3:PutObject"}},
+			found:    false,
+		},
+		{
+			name:     "wildcards are not expanded",
+			expected: PolicyStatement{Resource: StringOrSlice{"arn:aws:s3:::my-bucket/logs/*"}},
+			found:    false,
+		},
+		{
+			name:     "missing condition",
+			expected: PolicyStatement{Condition: map[string]map[string]StringOrSlice{"StringEquals": {"aws:PrincipalOrgID": {"o-123"}}}},
+			found:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := policy.FindStatement(tt.expected)
+			assert.Equalf(t, tt.found, statement != nil, "FindStatement(%s)", tt.expected)
+		})
+	}
+}
+
+func TestMatchesKMSKey(t *testing.T) {
+	arn := "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+	assert.True(t, matchesKMSKey(arn, arn))
+	assert.True(t, matchesKMSKey(arn, "1234abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.True(t, matchesKMSKey("arn:aws:kms:us-east-1:123456789012:alias/my-key", "alias/my-key"))
+	assert.False(t, matchesKMSKey(arn, "abcd-12ab-34cd-56ef-1234567890ab"))
+	assert.False(t, matchesKMSKey(arn, "alias/my-key"))
+}
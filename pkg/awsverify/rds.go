@@ -0,0 +1,149 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rdsFailedStatuses are the statuses of a DB instance that will not become available without an intervention.
+var rdsFailedStatuses = map[string]bool{
+	"failed":                              true,
+	"incompatible-network":                true,
+	"incompatible-option-group":           true,
+	"incompatible-parameters":             true,
+	"incompatible-restore":                true,
+	"inaccessible-encryption-credentials": true,
+	"storage-full":                        true,
+	"deleting":                            true,
+}
+
+// WaitForRDSInstanceAvailable waits until the DB instance is available. It waits DefaultRDSAvailableTimeout at
+// most, unless overridden with cloudprovider.WithMaxDuration.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - instanceID: The identifier of the DB instance.
+//   - opts: The polling settings.
+//
+// Returns:
+//   - *types.DBInstance: The DB instance, once available.
+func WaitForRDSInstanceAvailable(t *testing.T, adapter cloudprovider.AWSAdapter, instanceID string, opts ...cloudprovider.WaitOptFn) *types.DBInstance {
+	var current *types.DBInstance
+
+	err := waitForReadiness("DB instance "+instanceID, DefaultRDSAvailableTimeout, func(ctx context.Context) (bool, error) {
+		instance, err := describeRDSInstance(ctx, adapter, instanceID)
+		if err != nil {
+			return false, err
+		}
+
+		current = instance
+
+		return rdsInstanceAvailable(instance)
+	}, opts, rdsNotFoundCodes...)
+
+	state := "not found"
+	if current != nil {
+		state = derefString(current.DBInstanceStatus)
+	}
+
+	require.NoErrorf(t, err, "The DB instance %s is not available: %s", instanceID, state)
+
+	return current
+}
+
+// AssertRDSInstanceEngine checks the engine of the DB instance and its version.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - instanceID: The identifier of the DB instance.
+//   - engine: The expected engine (e.g.: "postgres").
+//   - version: The expected version, or a prefix of it (e.g.: "15" matches "15.4"). If empty, it is not checked.
+//
+// Example:
+//
+//	awsverify.AssertRDSInstanceEngine(t, s.GetAWS(), "my-db", "postgres", "15")
+func AssertRDSInstanceEngine(t *testing.T, adapter cloudprovider.AWSAdapter, instanceID, engine, version string) {
+	instance := getRDSInstance(t, adapter, instanceID)
+
+	assert.Equalf(t, engine, derefString(instance.Engine), "Unexpected engine for the DB instance %s", instanceID)
+
+	if actual := derefString(instance.EngineVersion); version != "" {
+		assert.Truef(t, matchesVersion(actual, version), "The DB instance %s runs version %s, expected %s", instanceID, actual, version)
+	}
+}
+
+// AssertRDSInstanceMultiAZ checks whether the DB instance is deployed in several availability zones.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - instanceID: The identifier of the DB instance.
+//   - multiAZ: True if the DB instance is expected to be multi-AZ.
+func AssertRDSInstanceMultiAZ(t *testing.T, adapter cloudprovider.AWSAdapter, instanceID string, multiAZ bool) {
+	instance := getRDSInstance(t, adapter, instanceID)
+
+	assert.Equalf(t, multiAZ, derefBool(instance.MultiAZ), "Unexpected multi-AZ setting for the DB instance %s", instanceID)
+}
+
+// AssertRDSInstanceEncrypted checks that the storage of the DB instance is encrypted.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - instanceID: The identifier of the DB instance.
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS). If empty, the key is not checked.
+func AssertRDSInstanceEncrypted(t *testing.T, adapter cloudprovider.AWSAdapter, instanceID, kmsKeyID string) {
+	instance := getRDSInstance(t, adapter, instanceID)
+
+	if !assert.Truef(t, derefBool(instance.StorageEncrypted), "The storage of the DB instance %s is not encrypted", instanceID) {
+		return
+	}
+
+	if keyID := derefString(instance.KmsKeyId); kmsKeyID != "" {
+		assertKMSKey(t, adapter, keyID, kmsKeyID, "the DB instance "+instanceID)
+	}
+}
+
+// getRDSInstance returns the DB instance.
+func getRDSInstance(t *testing.T, adapter cloudprovider.AWSAdapter, instanceID string) *types.DBInstance {
+	instance, err := retry("DB instance "+instanceID, func(ctx context.Context) (*types.DBInstance, error) {
+		return describeRDSInstance(ctx, adapter, instanceID)
+	}, rdsNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the DB instance %s", instanceID)
+
+	return instance
+}
+
+// rdsInstanceAvailable reports whether the DB instance is available, or an error if its status means that it will
+// not become available.
+func rdsInstanceAvailable(instance *types.DBInstance) (bool, error) {
+	status := derefString(instance.DBInstanceStatus)
+	if rdsFailedStatuses[status] {
+		return false, fmt.Errorf("the DB instance %s is %s", derefString(instance.DBInstanceIdentifier), status)
+	}
+
+	return status == "available", nil
+}
+
+// describeRDSInstance returns the DB instance.
+func describeRDSInstance(ctx context.Context, adapter cloudprovider.AWSAdapter, instanceID string) (*types.DBInstance, error) {
+	out, err := adapter.NewRDS().DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{DBInstanceIdentifier: &instanceID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.DBInstances) == 0 {
+		return nil, fmt.Errorf("the DB instance %s does not exist", instanceID)
+	}
+
+	return &out.DBInstances[0], nil
+}
@@ -0,0 +1,70 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRDSInstanceAvailable(t *testing.T) {
+	tests := []struct {
+		status    string
+		available bool
+		failed    bool
+	}{
+		{status: "available", available: true},
+		{status: "creating"},
+		{status: "backing-up"},
+		{status: "modifying"},
+		{status: "failed", failed: true},
+		{status: "storage-full", failed: true},
+		{status: "inaccessible-encryption-credentials", failed: true},
+		{status: "deleting", failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			available, err := rdsInstanceAvailable(&types.DBInstance{
+				DBInstanceIdentifier: aws.String("my-db"),
+				DBInstanceStatus:     aws.String(tt.status),
+			})
+
+			assert.Equal(t, tt.available, available)
+
+			if tt.failed {
+				assert.ErrorContains(t, err, "the DB instance my-db is "+tt.status)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDescribeRDSInstance(t *testing.T) {
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+
+		instances := ""
+		if r.Form.Get("DBInstanceIdentifier") == "my-db" {
+			instances = "<DBInstance><DBInstanceIdentifier>my-db</DBInstanceIdentifier><DBInstanceStatus>available</DBInstanceStatus></DBInstance>"
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(w, `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/">
+  <DescribeDBInstancesResult><DBInstances>%s</DBInstances></DescribeDBInstancesResult>
+</DescribeDBInstancesResponse>`, instances)
+	})
+
+	instance, err := describeRDSInstance(context.Background(), adapter, "my-db")
+	require.NoError(t, err)
+	assert.Equal(t, "available", derefString(instance.DBInstanceStatus))
+
+	_, err = describeRDSInstance(context.Background(), adapter, "other-db")
+	assert.ErrorContains(t, err, "the DB instance other-db does not exist")
+}
@@ -0,0 +1,93 @@
+package awsverify
+
+import (
+	"context"
+	"time"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+)
+
+// AWS error codes returned while a resource created by Terraform is not visible yet.
+var (
+	s3NotFoundCodes       = []string{"NotFound", "NoSuchBucket"}
+	iamNotFoundCodes      = []string{"NoSuchEntity"}
+	dynamoDBNotFoundCodes = []string{"ResourceNotFoundException", "TableNotFoundException"}
+	sqsNotFoundCodes      = []string{"AWS.SimpleQueueService.NonExistentQueue", "QueueDoesNotExist"}
+	snsNotFoundCodes      = []string{"NotFound"}
+	ecsNotFoundCodes      = []string{"ClusterNotFoundException"}
+	eksNotFoundCodes      = []string{"ResourceNotFoundException"}
+	rdsNotFoundCodes      = []string{"DBInstanceNotFound"}
+	// A function that is still being created or updated is rejected with a ResourceConflictException.
+	lambdaNotFoundCodes         = []string{"ResourceNotFoundException", "ResourceConflictException"}
+	kmsNotFoundCodes            = []string{"NotFoundException"}
+	ssmNotFoundCodes            = []string{"ParameterNotFound"}
+	secretsManagerNotFoundCodes = []string{"ResourceNotFoundException"}
+	logsNotFoundCodes           = []string{"ResourceNotFoundException"}
+	route53NotFoundCodes        = []string{"NoSuchHostedZone"}
+)
+
+// Default timeouts of the readiness waiters. They can be overridden with cloudprovider.WithMaxDuration.
+const (
+	DefaultECSSteadyStateTimeout = 10 * time.Minute
+	DefaultEKSActiveTimeout      = 30 * time.Minute
+	DefaultRDSAvailableTimeout   = 40 * time.Minute
+)
+
+// Polling intervals of the readiness waiters.
+const (
+	readinessInitialInterval = 5 * time.Second
+	readinessMaxInterval     = 30 * time.Second
+)
+
+// retry calls the AWS API until it succeeds, retrying the given error codes with the default backoff of
+// cloudprovider.Retry, so the helpers do not fail on resources that are not visible yet right after apply.
+//
+// Parameters:
+//   - description: What is retrieved, used to prefix the errors (e.g.: "bucket my-bucket").
+//   - fn: The call to the AWS API.
+//   - retryableCodes: The AWS error codes to retry.
+//
+// Returns:
+//   - T: The result of the call.
+//   - error: An error if the call did not succeed in time.
+func retry[T any](description string, fn func(ctx context.Context) (T, error), retryableCodes ...string) (T, error) {
+	return cloudprovider.Retry(context.TODO(), description, fn, cloudprovider.WithRetryableErrorCodes(retryableCodes...))
+}
+
+// waitUntil polls the condition until it is met, retrying the given error codes with the default backoff of
+// cloudprovider.WaitUntil.
+//
+// Parameters:
+//   - description: What is waited for, used to prefix the errors (e.g.: "table my-table").
+//   - condition: The condition. It returns true once met, false to poll again, or an error.
+//   - retryableCodes: The AWS error codes to retry.
+//
+// Returns:
+//   - error: An error if the condition is not met in time.
+func waitUntil(description string, condition func(ctx context.Context) (bool, error), retryableCodes ...string) error {
+	return cloudprovider.WaitUntil(context.TODO(), description, condition, cloudprovider.WithRetryableErrorCodes(retryableCodes...))
+}
+
+// waitForReadiness polls the condition until the resource is ready, with the default timeout of the resource and
+// a longer interval than the other helpers, since it typically takes minutes. The options override both.
+//
+// Parameters:
+//   - description: What is waited for, used to prefix the errors (e.g.: "service my-service").
+//   - timeout: The default maximum duration of the wait.
+//   - condition: The condition. It returns true once met, false to poll again, or an error.
+//   - opts: The polling settings, overriding the defaults.
+//   - retryableCodes: The AWS error codes to retry.
+//
+// Returns:
+//   - error: An error if the resource is not ready in time.
+func waitForReadiness(description string, timeout time.Duration, condition func(ctx context.Context) (bool, error),
+	opts []cloudprovider.WaitOptFn, retryableCodes ...string) error {
+	defaults := []cloudprovider.WaitOptFn{
+		cloudprovider.WithInitialInterval(readinessInitialInterval),
+		cloudprovider.WithMaxInterval(readinessMaxInterval),
+		cloudprovider.WithMaxDuration(timeout),
+		cloudprovider.WithRetryableErrorCodes(retryableCodes...),
+	}
+
+	return cloudprovider.WaitUntil(context.TODO(), description, condition, append(defaults, opts...)...)
+}
@@ -0,0 +1,110 @@
+package awsverify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ResolveRoute53Record returns the record set of the hosted zone with the given name and type. When several record
+// sets share them (weighted, latency... routing), the first one is returned.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - zoneID: The ID of the hosted zone.
+//   - name: The name of the record (e.g.: "api.example.com"), with or without the trailing dot.
+//   - recordType: The type of the record (e.g.: types.RRTypeA).
+//
+// Returns:
+//   - *types.ResourceRecordSet: The record set.
+//
+// Example:
+//
+//	record := awsverify.ResolveRoute53Record(t, s.GetAWS(), zoneID, "api.example.com", types.RRTypeCname)
+func ResolveRoute53Record(t *testing.T, adapter cloudprovider.AWSAdapter, zoneID, name string, recordType types.RRType) *types.ResourceRecordSet {
+	fqdn := normalizeRecordName(name)
+
+	record, err := retry("hosted zone "+zoneID, func(ctx context.Context) (*types.ResourceRecordSet, error) {
+		// The record sets are sorted by name and type, so the first one listed from them is the one looked for,
+		// if it exists.
+		out, err := adapter.NewRoute53().ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
+			HostedZoneId:    &zoneID,
+			StartRecordName: &fqdn,
+			StartRecordType: recordType,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range out.ResourceRecordSets {
+			set := &out.ResourceRecordSets[i]
+			if normalizeRecordName(derefString(set.Name)) == fqdn && set.Type == recordType {
+				return set, nil
+			}
+		}
+
+		return nil, fmt.Errorf("the %s record %s does not exist", recordType, fqdn)
+	}, route53NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to resolve the %s record %s in the hosted zone %s", recordType, name, zoneID)
+
+	return record
+}
+
+// AssertRoute53RecordValues checks the values of the record, regardless of their order.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - zoneID: The ID of the hosted zone.
+//   - name: The name of the record (e.g.: "api.example.com").
+//   - recordType: The type of the record (e.g.: types.RRTypeA).
+//   - values: The expected values. For an alias record, the DNS name of its target. The values of TXT records
+//     are quoted (e.g.: `"v=spf1 -all"`).
+//
+// Example:
+//
+//	awsverify.AssertRoute53RecordValues(t, s.GetAWS(), zoneID, "www.example.com", types.RRTypeA, "203.0.113.10")
+func AssertRoute53RecordValues(t *testing.T, adapter cloudprovider.AWSAdapter, zoneID, name string, recordType types.RRType, values ...string) {
+	record := ResolveRoute53Record(t, adapter, zoneID, name, recordType)
+
+	if record.AliasTarget != nil {
+		actual := strings.TrimSuffix(strings.ToLower(derefString(record.AliasTarget.DNSName)), ".")
+		expected := make([]string, 0, len(values))
+
+		for _, value := range values {
+			expected = append(expected, strings.TrimSuffix(strings.ToLower(value), "."))
+		}
+
+		assert.Equalf(t, expected, []string{actual}, "Unexpected alias target for the %s record %s", recordType, name)
+
+		return
+	}
+
+	actual := make([]string, 0, len(record.ResourceRecords))
+	for _, resourceRecord := range record.ResourceRecords {
+		actual = append(actual, derefString(resourceRecord.Value))
+	}
+
+	assert.ElementsMatchf(t, values, actual, "Unexpected values for the %s record %s", recordType, name)
+}
+
+// normalizeRecordName returns the name of a record in a form that can be compared with the names listed by
+// Route53: lower case, fully qualified, and with its wildcard unescaped (e.g.: "*.Example.com" and
+// "\052.example.com." both give "*.example.com.").
+func normalizeRecordName(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), `\052`, "*")
+
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+
+	return name
+}
@@ -0,0 +1,14 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRecordName(t *testing.T) {
+	assert.Equal(t, "api.example.com.", normalizeRecordName("API.example.com"))
+	assert.Equal(t, "api.example.com.", normalizeRecordName("api.example.com."))
+	assert.Equal(t, "*.example.com.", normalizeRecordName(`\052.example.com.`))
+	assert.Equal(t, "*.example.com.", normalizeRecordName("*.Example.com"))
+}
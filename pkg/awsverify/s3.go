@@ -0,0 +1,285 @@
+package awsverify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertS3BucketExists checks that the bucket exists and is reachable with the credentials of the adapter.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//
+// Example:
+//
+//	awsverify.AssertS3BucketExists(t, s.GetAWS(), "my-bucket")
+func AssertS3BucketExists(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string) {
+	_, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.HeadBucketOutput, error) {
+		return adapter.NewS3().HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "The bucket %s does not exist or is not reachable", bucket)
+}
+
+// AssertS3BucketVersioningEnabled checks that versioning is enabled on the bucket.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+func AssertS3BucketVersioningEnabled(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string) {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetBucketVersioningOutput, error) {
+		return adapter.NewS3().GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the versioning of the bucket %s", bucket)
+
+	assert.Equalf(t, types.BucketVersioningStatusEnabled, out.Status, "Versioning is not enabled on the bucket %s", bucket)
+}
+
+// AssertS3BucketEncryption checks the default encryption of the bucket.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//   - algorithm: The expected algorithm, types.ServerSideEncryptionAes256 (SSE-S3) or types.ServerSideEncryptionAwsKms (SSE-KMS).
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS), for SSE-KMS. If empty, the key is
+//     not checked.
+//
+// Example:
+//
+//	awsverify.AssertS3BucketEncryption(t, s.GetAWS(), "my-bucket", types.ServerSideEncryptionAwsKms, "alias/my-key")
+func AssertS3BucketEncryption(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string, algorithm types.ServerSideEncryption, kmsKeyID string) {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetBucketEncryptionOutput, error) {
+		return adapter.NewS3().GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the encryption of the bucket %s", bucket)
+	require.NotNilf(t, out.ServerSideEncryptionConfiguration, "The bucket %s has no default encryption", bucket)
+
+	byDefault := findDefaultEncryption(out.ServerSideEncryptionConfiguration.Rules, algorithm)
+	if byDefault == nil {
+		assert.Failf(t, "Unexpected bucket encryption", "The bucket %s is not encrypted with %s by default", bucket, algorithm)
+		return
+	}
+
+	if kmsKeyID == "" {
+		return
+	}
+
+	// The key is not returned when the bucket is encrypted with the key managed by AWS.
+	keyID := derefString(byDefault.KMSMasterKeyID)
+	if !assert.NotEmptyf(t, keyID, "The bucket %s is encrypted with the AWS-managed key, expected %s", bucket, kmsKeyID) {
+		return
+	}
+
+	assertKMSKey(t, adapter, keyID, kmsKeyID, "the bucket "+bucket)
+}
+
+// AssertS3BucketPublicAccessBlocked checks that the four settings of the public access block of the bucket are
+// enabled.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+func AssertS3BucketPublicAccessBlocked(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string) {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetPublicAccessBlockOutput, error) {
+		return adapter.NewS3().GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the public access block of the bucket %s", bucket)
+	require.NotNilf(t, out.PublicAccessBlockConfiguration, "The bucket %s has no public access block", bucket)
+
+	disabled := disabledPublicAccessBlocks(out.PublicAccessBlockConfiguration)
+	assert.Emptyf(t, disabled, "The public access block of the bucket %s does not enable %v", bucket, disabled)
+}
+
+// AssertS3BucketLifecycleRule checks that the bucket has an enabled lifecycle rule with the given ID, and returns
+// it for further assertions (expiration, transitions...).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//   - ruleID: The ID of the lifecycle rule.
+//
+// Returns:
+//   - *types.LifecycleRule: The lifecycle rule.
+//
+// Example:
+//
+//	rule := awsverify.AssertS3BucketLifecycleRule(t, s.GetAWS(), "my-bucket", "expire-logs")
+//	assert.Equal(t, int32(30), *rule.Expiration.Days)
+func AssertS3BucketLifecycleRule(t *testing.T, adapter cloudprovider.AWSAdapter, bucket, ruleID string) *types.LifecycleRule {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+		return adapter.NewS3().GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the lifecycle configuration of the bucket %s", bucket)
+
+	rule := findLifecycleRule(out.Rules, ruleID)
+	require.NotNilf(t, rule, "The bucket %s has no lifecycle rule %s", bucket, ruleID)
+
+	assert.Equalf(t, types.ExpirationStatusEnabled, rule.Status, "The lifecycle rule %s of the bucket %s is not enabled", ruleID, bucket)
+
+	return rule
+}
+
+// GetS3BucketPolicy returns the parsed policy of the bucket.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//
+// Returns:
+//   - *PolicyDocument: The bucket policy.
+func GetS3BucketPolicy(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string) *PolicyDocument {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetBucketPolicyOutput, error) {
+		return adapter.NewS3().GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the policy of the bucket %s", bucket)
+
+	policy, err := ParsePolicyDocument(derefString(out.Policy))
+	require.NoErrorf(t, err, "Failed to parse the policy of the bucket %s", bucket)
+
+	return policy
+}
+
+// AssertS3BucketPolicyStatement checks that the policy of the bucket has a statement that contains the expected
+// one (see PolicyStatement.Contains).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//   - expected: The expected statement. Its empty fields are not checked.
+//
+// Example:
+//
+//	awsverify.AssertS3BucketPolicyStatement(t, s.GetAWS(), "my-bucket", awsverify.PolicyStatement{
+//	    Effect:    "Deny",
+//	    Action:    awsverify.StringOrSlice{"s3:*"},
+//	    Condition: map[string]map[string]awsverify.StringOrSlice{"Bool": {"aws:SecureTransport": {"false"}}},
+//	})
+func AssertS3BucketPolicyStatement(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string, expected PolicyStatement) {
+	assertPolicyHasStatement(t, GetS3BucketPolicy(t, adapter, bucket), expected, "the policy of the bucket "+bucket)
+}
+
+// AssertS3BucketTags checks that the bucket has the expected tags. Other tags are ignored.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - bucket: The name of the bucket.
+//   - expected: The expected tags.
+func AssertS3BucketTags(t *testing.T, adapter cloudprovider.AWSAdapter, bucket string, expected map[string]string) {
+	out, err := retry("bucket "+bucket, func(ctx context.Context) (*s3.GetBucketTaggingOutput, error) {
+		return adapter.NewS3().GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: &bucket})
+	}, s3NotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the tags of the bucket %s", bucket)
+
+	assertTags(t, s3TagMap(out.TagSet), expected, "the bucket "+bucket)
+}
+
+// findDefaultEncryption returns the default encryption of the rule that uses the algorithm, or nil if none does.
+func findDefaultEncryption(rules []types.ServerSideEncryptionRule, algorithm types.ServerSideEncryption) *types.ServerSideEncryptionByDefault {
+	for _, rule := range rules {
+		if byDefault := rule.ApplyServerSideEncryptionByDefault; byDefault != nil && byDefault.SSEAlgorithm == algorithm {
+			return byDefault
+		}
+	}
+
+	return nil
+}
+
+// disabledPublicAccessBlocks returns the settings of the public access block that are not enabled.
+func disabledPublicAccessBlocks(block *types.PublicAccessBlockConfiguration) []string {
+	settings := []struct {
+		name    string
+		enabled *bool
+	}{
+		{"BlockPublicAcls", block.BlockPublicAcls},
+		{"BlockPublicPolicy", block.BlockPublicPolicy},
+		{"IgnorePublicAcls", block.IgnorePublicAcls},
+		{"RestrictPublicBuckets", block.RestrictPublicBuckets},
+	}
+
+	var disabled []string
+
+	for _, setting := range settings {
+		if !derefBool(setting.enabled) {
+			disabled = append(disabled, setting.name)
+		}
+	}
+
+	return disabled
+}
+
+// findLifecycleRule returns the lifecycle rule with the given ID, or nil if there is none.
+func findLifecycleRule(rules []types.LifecycleRule, ruleID string) *types.LifecycleRule {
+	for i := range rules {
+		if derefString(rules[i].ID) == ruleID {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// s3TagMap indexes the tags of a bucket by key.
+func s3TagMap(tagSet []types.Tag) map[string]string {
+	tags := make(map[string]string, len(tagSet))
+	for _, tag := range tagSet {
+		tags[derefString(tag.Key)] = derefString(tag.Value)
+	}
+
+	return tags
+}
+
+// matchesKMSKey reports whether the actual key (usually an ARN) is the expected key as written: the same ID or
+// ARN, or the ID at the end of the ARN. An alias only matches the ARN of the alias, not the key it points to (see
+// assertKMSKey).
+func matchesKMSKey(actual, expected string) bool {
+	return actual == expected || strings.HasSuffix(actual, "/"+expected) || strings.HasSuffix(actual, ":"+expected)
+}
+
+// assertPolicyHasStatement checks that the policy has a statement that contains the expected one.
+func assertPolicyHasStatement(t *testing.T, policy *PolicyDocument, expected PolicyStatement, what string) {
+	if policy.FindStatement(expected) != nil {
+		return
+	}
+
+	statements := make([]string, 0, len(policy.Statement))
+	for _, statement := range policy.Statement {
+		statements = append(statements, "  - "+statement.String())
+	}
+
+	assert.Failf(t, "Policy statement not found", "No statement of %s contains %s, found:\n%s",
+		what, expected, strings.Join(statements, "\n"))
+}
+
+// assertTags checks that every expected tag is set with the expected value.
+func assertTags(t *testing.T, tags, expected map[string]string, what string) {
+	for key, value := range expected {
+		actual, found := tags[key]
+		if assert.Truef(t, found, "The tag %s is not set on %s", key, what) {
+			assert.Equalf(t, value, actual, "Unexpected value for the tag %s on %s", key, what)
+		}
+	}
+}
+
+func derefString(s *string) string {
+	return aws.ToString(s)
+}
+
+func derefBool(b *bool) bool {
+	return aws.ToBool(b)
+}
@@ -0,0 +1,66 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDefaultEncryption(t *testing.T) {
+	rules := []types.ServerSideEncryptionRule{
+		{BucketKeyEnabled: aws.Bool(true)},
+		{ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{
+			SSEAlgorithm:   types.ServerSideEncryptionAwsKms,
+			KMSMasterKeyID: aws.String("arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"),
+		}},
+	}
+
+	byDefault := findDefaultEncryption(rules, types.ServerSideEncryptionAwsKms)
+	require.NotNil(t, byDefault)
+	assert.Equal(t, "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab", *byDefault.KMSMasterKeyID)
+
+	assert.Nil(t, findDefaultEncryption(rules, types.ServerSideEncryptionAes256))
+	assert.Nil(t, findDefaultEncryption(nil, types.ServerSideEncryptionAes256))
+}
+
+func TestDisabledPublicAccessBlocks(t *testing.T) {
+	assert.Empty(t, disabledPublicAccessBlocks(&types.PublicAccessBlockConfiguration{
+		BlockPublicAcls:       aws.Bool(true),
+		BlockPublicPolicy:     aws.Bool(true),
+		IgnorePublicAcls:      aws.Bool(true),
+		RestrictPublicBuckets: aws.Bool(true),
+	}))
+
+	assert.Equal(t, []string{"BlockPublicPolicy", "RestrictPublicBuckets"}, disabledPublicAccessBlocks(&types.PublicAccessBlockConfiguration{
+		BlockPublicAcls:   aws.Bool(true),
+		BlockPublicPolicy: aws.Bool(false),
+		IgnorePublicAcls:  aws.Bool(true),
+	}))
+}
+
+func TestFindLifecycleRule(t *testing.T) {
+	rules := []types.LifecycleRule{
+		{ID: aws.String("expire-logs"), Status: types.ExpirationStatusEnabled},
+		{ID: aws.String("archive"), Status: types.ExpirationStatusDisabled},
+	}
+
+	rule := findLifecycleRule(rules, "archive")
+	require.NotNil(t, rule)
+	assert.Equal(t, types.ExpirationStatusDisabled, rule.Status)
+	assert.Same(t, &rules[1], rule, "The rule is returned for further assertions")
+
+	assert.Nil(t, findLifecycleRule(rules, "transition"))
+}
+
+func TestS3TagMap(t *testing.T) {
+	tags := s3TagMap([]types.Tag{
+		{Key: aws.String("env"), Value: aws.String("test")},
+		{Key: aws.String("team"), Value: aws.String("")},
+	})
+
+	assert.Equal(t, map[string]string{"env": "test", "team": ""}, tags)
+	assert.Empty(t, s3TagMap(nil))
+}
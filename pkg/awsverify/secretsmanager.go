@@ -0,0 +1,107 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetSecretValue reads the current value of the secret.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - secretID: The name or ARN of the secret.
+//
+// Returns:
+//   - string: The value of the secret.
+//
+// Example:
+//
+//	password := awsverify.GetSecretValue(t, s.GetAWS(), "my-app/database/password")
+func GetSecretValue(t *testing.T, adapter cloudprovider.AWSAdapter, secretID string) string {
+	out, err := retry("secret "+secretID, func(ctx context.Context) (*secretsmanager.GetSecretValueOutput, error) {
+		return adapter.NewSecretsManager().GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &secretID})
+	}, secretsManagerNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to read the secret %s", secretID)
+
+	if out.SecretString != nil {
+		return *out.SecretString
+	}
+
+	return string(out.SecretBinary)
+}
+
+// AssertSecretJSONKeys checks that the value of the secret is a JSON object with the given keys (e.g.: the
+// "username" and "password" of a database).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - secretID: The name or ARN of the secret.
+//   - keys: The keys expected in the value.
+//
+// Example:
+//
+//	awsverify.AssertSecretJSONKeys(t, s.GetAWS(), "my-app/database", "username", "password", "host")
+func AssertSecretJSONKeys(t *testing.T, adapter cloudprovider.AWSAdapter, secretID string, keys ...string) {
+	missing, err := missingJSONKeys(GetSecretValue(t, adapter, secretID), keys)
+	require.NoErrorf(t, err, "The value of the secret %s is not a JSON object", secretID)
+
+	assert.Emptyf(t, missing, "The value of the secret %s lacks some keys", secretID)
+}
+
+// AssertSecretEncrypted checks that the secret is encrypted with the given KMS key, instead of the key managed by
+// AWS.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - secretID: The name or ARN of the secret.
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS).
+func AssertSecretEncrypted(t *testing.T, adapter cloudprovider.AWSAdapter, secretID, kmsKeyID string) {
+	out, err := retry("secret "+secretID, func(ctx context.Context) (*secretsmanager.DescribeSecretOutput, error) {
+		return adapter.NewSecretsManager().DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: &secretID})
+	}, secretsManagerNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the secret %s", secretID)
+
+	// The key is not returned when the secret is encrypted with the key managed by AWS.
+	keyID := derefString(out.KmsKeyId)
+	if !assert.NotEmptyf(t, keyID, "The secret %s is encrypted with the key managed by AWS", secretID) {
+		return
+	}
+
+	assertKMSKey(t, adapter, keyID, kmsKeyID, "the secret "+secretID)
+}
+
+// missingJSONKeys returns the keys missing from the JSON object.
+//
+// Parameters:
+//   - value: The JSON object.
+//   - keys: The expected keys.
+//
+// Returns:
+//   - []string: The keys missing from the object.
+//   - error: An error if the value is not a JSON object.
+func missingJSONKeys(value string, keys []string) ([]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return nil, fmt.Errorf("failed to decode the JSON object: %v", err)
+	}
+
+	var missing []string
+
+	for _, key := range keys {
+		if _, found := object[key]; !found {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing, nil
+}
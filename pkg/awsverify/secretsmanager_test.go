@@ -0,0 +1,20 @@
+package awsverify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingJSONKeys(t *testing.T) {
+	missing, err := missingJSONKeys(`{"username": "admin", "password": null}`, []string{"username", "password", "host"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host"}, missing)
+
+	missing, err = missingJSONKeys(`{"username": "admin"}`, []string{"username"})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	_, err = missingJSONKeys("s3cr3t", []string{"username"})
+	assert.ErrorContains(t, err, "failed to decode the JSON object")
+}
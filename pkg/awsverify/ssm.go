@@ -0,0 +1,94 @@
+package awsverify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetSSMParameter reads the parameter. The SecureString parameters are decrypted.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name or ARN of the parameter.
+//
+// Returns:
+//   - *types.Parameter: The parameter, with its value.
+//
+// Example:
+//
+//	value := aws.ToString(awsverify.GetSSMParameter(t, s.GetAWS(), "/my-app/database/url").Value)
+func GetSSMParameter(t *testing.T, adapter cloudprovider.AWSAdapter, name string) *types.Parameter {
+	out, err := retry("parameter "+name, func(ctx context.Context) (*ssm.GetParameterOutput, error) {
+		return adapter.NewSSM().GetParameter(ctx, &ssm.GetParameterInput{Name: &name, WithDecryption: aws.Bool(true)})
+	}, ssmNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to read the parameter %s", name)
+
+	return out.Parameter
+}
+
+// AssertSSMParameterValue checks the value of the parameter. The SecureString parameters are decrypted.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name or ARN of the parameter.
+//   - value: The expected value.
+func AssertSSMParameterValue(t *testing.T, adapter cloudprovider.AWSAdapter, name, value string) {
+	parameter := GetSSMParameter(t, adapter, name)
+
+	assert.Equalf(t, value, derefString(parameter.Value), "Unexpected value for the parameter %s", name)
+}
+
+// AssertSSMParameterSecure checks that the parameter is a SecureString, encrypted with the given key.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - adapter: The AWS Cloud Provider (Client).
+//   - name: The name of the parameter.
+//   - kmsKeyID: The expected KMS key (ID, alias or ARN, resolved through KMS). If empty, the key is not checked.
+func AssertSSMParameterSecure(t *testing.T, adapter cloudprovider.AWSAdapter, name, kmsKeyID string) {
+	var parameter *types.ParameterMetadata
+
+	// The parameters are listed by filter, so a parameter that is not visible yet is just missing from the list.
+	err := waitUntil("parameter "+name, func(ctx context.Context) (bool, error) {
+		var err error
+		parameter, err = describeSSMParameter(ctx, adapter, name)
+
+		return parameter != nil, err
+	}, ssmNotFoundCodes...)
+	require.NoErrorf(t, err, "Failed to get the parameter %s", name)
+
+	if !assert.Equalf(t, types.ParameterTypeSecureString, parameter.Type, "The parameter %s is not a SecureString", name) {
+		return
+	}
+
+	if kmsKeyID != "" {
+		assertKMSKey(t, adapter, derefString(parameter.KeyId), kmsKeyID, "the parameter "+name)
+	}
+}
+
+// describeSSMParameter returns the metadata of the parameter, which holds its key, or nil if it is not listed.
+func describeSSMParameter(ctx context.Context, adapter cloudprovider.AWSAdapter, name string) (*types.ParameterMetadata, error) {
+	out, err := adapter.NewSSM().DescribeParameters(ctx, &ssm.DescribeParametersInput{
+		ParameterFilters: []types.ParameterStringFilter{{Key: aws.String("Name"), Option: aws.String("Equals"), Values: []string{name}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range out.Parameters {
+		if derefString(out.Parameters[i].Name) == name {
+			return &out.Parameters[i], nil
+		}
+	}
+
+	return nil, nil
+}
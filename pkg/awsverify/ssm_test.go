@@ -0,0 +1,72 @@
+package awsverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSM answers DescribeParameters with the SecureString parameters, encrypted with the key ID of
+// testKMSKeyARN, and lets fakeKMS answer the KMS calls.
+func fakeSSM(parameters ...string) http.HandlerFunc {
+	kms := fakeKMS(map[string]string{
+		testKMSKeyARN:                          testKMSKeyARN,
+		"alias/my-key":                         testKMSKeyARN,
+		"1234abcd-12ab-34cd-56ef-1234567890ab": testKMSKeyARN,
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("X-Amz-Target"), "TrentService.") {
+			kms(w, r)
+			return
+		}
+
+		var input struct {
+			ParameterFilters []struct{ Values []string }
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		var listed []string
+
+		for _, filter := range input.ParameterFilters {
+			for _, name := range filter.Values {
+				for _, parameter := range parameters {
+					if parameter == name {
+						listed = append(listed, fmt.Sprintf(`{"Name":%q,"Type":"SecureString","KeyId":"1234abcd-12ab-34cd-56ef-1234567890ab"}`, name))
+					}
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = fmt.Fprintf(w, `{"Parameters":[%s]}`, strings.Join(listed, ","))
+	}
+}
+
+func TestDescribeSSMParameter(t *testing.T) {
+	adapter := newTestAdapter(t, fakeSSM("/my-app/api-key"))
+
+	parameter, err := describeSSMParameter(context.Background(), adapter, "/my-app/api-key")
+	require.NoError(t, err)
+	require.NotNil(t, parameter)
+	assert.Equal(t, types.ParameterTypeSecureString, parameter.Type)
+
+	parameter, err = describeSSMParameter(context.Background(), adapter, "/my-app/other")
+	require.NoError(t, err)
+	assert.Nil(t, parameter, "A parameter that is not listed is not an error, it may not be visible yet")
+}
+
+func TestAssertSSMParameterSecure(t *testing.T) {
+	adapter := newTestAdapter(t, fakeSSM("/my-app/api-key"))
+
+	// SSM returns the ID of the key, which the alias is resolved to.
+	AssertSSMParameterSecure(t, adapter, "/my-app/api-key", "alias/my-key")
+	AssertSSMParameterSecure(t, adapter, "/my-app/api-key", testKMSKeyARN)
+}
@@ -3,20 +3,28 @@ package cloudprovider
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awscfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // AWSAdapter defines an interface for creating various AWS service clients.
@@ -50,37 +58,167 @@ type AWSAdapter interface {
 
 	// NewEKS creates a new Elastic Kubernetes Service (EKS) client.
 	NewEKS() *eks.Client
+
+	// NewSTS creates a new Security Token Service (STS) client.
+	NewSTS() *sts.Client
+
+	// NewLambda creates a new Lambda client.
+	NewLambda() *lambda.Client
+
+	// NewKMS creates a new Key Management Service (KMS) client.
+	NewKMS() *kms.Client
+
+	// NewSSM creates a new Systems Manager (SSM) client.
+	NewSSM() *ssm.Client
+
+	// NewSecretsManager creates a new Secrets Manager client.
+	NewSecretsManager() *secretsmanager.Client
+
+	// NewCloudWatchLogs creates a new CloudWatch Logs client.
+	NewCloudWatchLogs() *cloudwatchlogs.Client
+
+	// NewRoute53 creates a new Route 53 client.
+	NewRoute53() *route53.Client
+
+	// GetRegion returns the AWS region of the clients.
+	GetRegion() string
+
+	// GetCallerIdentity returns the identity (account, ARN) the clients act as.
+	GetCallerIdentity(ctx context.Context) (*CallerIdentity, error)
+
+	// GetTerraformEnvVars returns the environment variables that make Terraform use the credentials and the
+	// region of the clients.
+	GetTerraformEnvVars(ctx context.Context) (map[string]string, error)
 }
 
 // AWS implements the AWSAdapter interface and holds the configuration for AWS services.
 type AWS struct {
-	Region string
-	cfg    aws.Config
+	Region           string
+	cfg              aws.Config
+	serviceEndpoints map[string]string
 }
 
 // NewAWS creates a new instance of AWS with the specified region.
-// It requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables to be set.
+// The credentials are resolved through the default AWS credential chain (environment variables, shared
+// configuration and credentials files, SSO, web identity, instance roles...), optionally from a named profile
+// (see WithProfile) and assuming a role (see WithAssumeRole). When every client targets a custom endpoint (see
+// WithEndpoint) and no credentials are set in the environment, test credentials are used instead.
+//
+// The profile, the assumed role and the test credentials only apply to the clients: pass GetTerraformEnvVars to
+// Terraform so that it runs with the same credentials.
 //
 // Parameters:
-//   - region: The AWS region to use.
+//   - region: The AWS region to use. If empty, the region of the environment or the profile is used.
+//   - opts: A list of option functions to modify the options.
 //
 // Returns:
 //   - AWSAdapter: An interface for creating AWS service clients.
 //   - error: An error if the AWS configuration could not be loaded.
-func NewAWS(region string) (AWSAdapter, error) {
-	if os.Getenv("AWS_ACCESS_KEY_ID") == "" || os.Getenv("AWS_SECRET_ACCESS_KEY") == "" {
-		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+//
+// Example:
+//
+//	aws, err := NewAWS("us-east-1", WithProfile("sandbox"), WithAssumeRole("arn:aws:iam::123456789012:role/tests", "tftest", ""))
+//	if err != nil {
+//	    log.Fatalf("Error creating the AWS client: %v", err)
+//	}
+//	s3Client := aws.NewS3()
+func NewAWS(region string, opts ...AWSOptFn) (AWSAdapter, error) {
+	o := &awsOptions{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
 	}
 
-	cfg, err := awscfg.LoadDefaultConfig(context.TODO(),
+	loadOpts := []func(*awscfg.LoadOptions) error{
 		awscfg.WithRegion(region),
-	)
+	}
+
+	if o.profile != "" {
+		loadOpts = append(loadOpts, awscfg.WithSharedConfigProfile(o.profile))
+	}
+
+	if o.usesTestCredentials() {
+		loadOpts = append(loadOpts, awscfg.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(TestCredential, TestCredential, "")))
+	}
+
+	cfg, err := awscfg.LoadDefaultConfig(context.TODO(), loadOpts...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %v", err)
 	}
 
-	return &AWS{Region: region, cfg: cfg}, nil
+	if o.endpoint != "" {
+		cfg.BaseEndpoint = aws.String(o.endpoint)
+	}
+
+	a := &AWS{Region: cfg.Region, cfg: cfg, serviceEndpoints: o.serviceEndpoints}
+
+	if o.assumeRole != nil {
+		// The source credentials assume the role, through the STS endpoint of the clients.
+		a.cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(a.NewSTS(),
+			o.assumeRole.roleARN, func(p *stscreds.AssumeRoleOptions) {
+				p.RoleSessionName = o.assumeRole.sessionName
+
+				if o.assumeRole.externalID != "" {
+					p.ExternalID = aws.String(o.assumeRole.externalID)
+				}
+			}))
+	}
+
+	return a, nil
+}
+
+// GetRegion returns the AWS region of the clients.
+//
+// Returns:
+//   - string: The AWS region.
+func (a *AWS) GetRegion() string {
+	return a.Region
+}
+
+// GetTerraformEnvVars returns the environment variables that make Terraform (and its AWS provider) use the
+// credentials and the region of the clients, whether they come from the environment, a profile, an assumed role
+// or the test credentials. The credentials of an assumed role are temporary: they are valid for an hour.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//
+// Returns:
+//   - map[string]string: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN (empty for long-term
+//     credentials), AWS_REGION and AWS_DEFAULT_REGION.
+//   - error: An error if the credentials could not be resolved.
+//
+// Example:
+//
+//	envVars, err := aws.GetTerraformEnvVars(context.TODO())
+//	if err != nil {
+//	    log.Fatalf("Error resolving the AWS credentials: %v", err)
+//	}
+//	options := &terraform.Options{TerraformDir: "../module", EnvVars: envVars}
+func (a *AWS) GetTerraformEnvVars(ctx context.Context) (map[string]string, error) {
+	creds, err := a.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the AWS credentials: %v", err)
+	}
+
+	return map[string]string{
+		"AWS_ACCESS_KEY_ID":     creds.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": creds.SecretAccessKey,
+		"AWS_SESSION_TOKEN":     creds.SessionToken,
+		"AWS_REGION":            a.Region,
+		"AWS_DEFAULT_REGION":    a.Region,
+	}, nil
+}
+
+// endpointFor returns the endpoint of the given service, or nil to keep the one of the configuration.
+func (a *AWS) endpointFor(service string) *string {
+	if endpoint, ok := a.serviceEndpoints[service]; ok {
+		return aws.String(endpoint)
+	}
+
+	return a.cfg.BaseEndpoint
 }
 
 // NewSNS creates a new Simple Notification Service (SNS) client.
@@ -88,7 +226,9 @@ func NewAWS(region string) (AWSAdapter, error) {
 // Returns:
 //   - *sns.Client: A new SNS client.
 func (a *AWS) NewSNS() *sns.Client {
-	return sns.NewFromConfig(a.cfg)
+	return sns.NewFromConfig(a.cfg, func(o *sns.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceSNS)
+	})
 }
 
 // NewSQS creates a new Simple Queue Service (SQS) client.
@@ -96,7 +236,9 @@ func (a *AWS) NewSNS() *sns.Client {
 // Returns:
 //   - *sqs.Client: A new SQS client.
 func (a *AWS) NewSQS() *sqs.Client {
-	return sqs.NewFromConfig(a.cfg)
+	return sqs.NewFromConfig(a.cfg, func(o *sqs.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceSQS)
+	})
 }
 
 // NewS3 creates a new Simple Storage Service (S3) client.
@@ -104,7 +246,12 @@ func (a *AWS) NewSQS() *sqs.Client {
 // Returns:
 //   - *s3.Client: A new S3 client.
 func (a *AWS) NewS3() *s3.Client {
-	return s3.NewFromConfig(a.cfg)
+	return s3.NewFromConfig(a.cfg, func(o *s3.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceS3)
+
+		// Local stand-ins do not resolve virtual-hosted bucket names.
+		o.UsePathStyle = o.BaseEndpoint != nil
+	})
 }
 
 // NewRDS creates a new Relational Database Service (RDS) client.
@@ -112,7 +259,9 @@ func (a *AWS) NewS3() *s3.Client {
 // Returns:
 //   - *rds.Client: A new RDS client.
 func (a *AWS) NewRDS() *rds.Client {
-	return rds.NewFromConfig(a.cfg)
+	return rds.NewFromConfig(a.cfg, func(o *rds.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceRDS)
+	})
 }
 
 // NewEC2 creates a new Elastic Compute Cloud (EC2) client.
@@ -120,7 +269,9 @@ func (a *AWS) NewRDS() *rds.Client {
 // Returns:
 //   - *ec2.Client: A new EC2 client.
 func (a *AWS) NewEC2() *ec2.Client {
-	return ec2.NewFromConfig(a.cfg)
+	return ec2.NewFromConfig(a.cfg, func(o *ec2.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceEC2)
+	})
 }
 
 // NewIAM creates a new Identity and Access Management (IAM) client.
@@ -128,7 +279,9 @@ func (a *AWS) NewEC2() *ec2.Client {
 // Returns:
 //   - *iam.Client: A new IAM client.
 func (a *AWS) NewIAM() *iam.Client {
-	return iam.NewFromConfig(a.cfg)
+	return iam.NewFromConfig(a.cfg, func(o *iam.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceIAM)
+	})
 }
 
 // NewDynamoDB creates a new DynamoDB client.
@@ -136,7 +289,9 @@ func (a *AWS) NewIAM() *iam.Client {
 // Returns:
 //   - *dynamodb.Client: A new DynamoDB client.
 func (a *AWS) NewDynamoDB() *dynamodb.Client {
-	return dynamodb.NewFromConfig(a.cfg)
+	return dynamodb.NewFromConfig(a.cfg, func(o *dynamodb.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceDynamoDB)
+	})
 }
 
 // NewAutoScaling creates a new Auto Scaling client.
@@ -144,7 +299,9 @@ func (a *AWS) NewDynamoDB() *dynamodb.Client {
 // Returns:
 //   - *autoscaling.Client: A new Auto Scaling client.
 func (a *AWS) NewAutoScaling() *autoscaling.Client {
-	return autoscaling.NewFromConfig(a.cfg)
+	return autoscaling.NewFromConfig(a.cfg, func(o *autoscaling.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceAutoScaling)
+	})
 }
 
 // NewECS creates a new Elastic Container Service (ECS) client.
@@ -152,7 +309,9 @@ func (a *AWS) NewAutoScaling() *autoscaling.Client {
 // Returns:
 //   - *ecs.Client: A new ECS client.
 func (a *AWS) NewECS() *ecs.Client {
-	return ecs.NewFromConfig(a.cfg)
+	return ecs.NewFromConfig(a.cfg, func(o *ecs.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceECS)
+	})
 }
 
 // NewEKS creates a new Elastic Kubernetes Service (EKS) client.
@@ -160,5 +319,77 @@ func (a *AWS) NewECS() *ecs.Client {
 // Returns:
 //   - *eks.Client: A new EKS client.
 func (a *AWS) NewEKS() *eks.Client {
-	return eks.NewFromConfig(a.cfg)
+	return eks.NewFromConfig(a.cfg, func(o *eks.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceEKS)
+	})
+}
+
+// NewSTS creates a new Security Token Service (STS) client.
+//
+// Returns:
+//   - *sts.Client: A new STS client.
+func (a *AWS) NewSTS() *sts.Client {
+	return sts.NewFromConfig(a.cfg, func(o *sts.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceSTS)
+	})
+}
+
+// NewLambda creates a new Lambda client.
+//
+// Returns:
+//   - *lambda.Client: A new Lambda client.
+func (a *AWS) NewLambda() *lambda.Client {
+	return lambda.NewFromConfig(a.cfg, func(o *lambda.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceLambda)
+	})
+}
+
+// NewKMS creates a new Key Management Service (KMS) client.
+//
+// Returns:
+//   - *kms.Client: A new KMS client.
+func (a *AWS) NewKMS() *kms.Client {
+	return kms.NewFromConfig(a.cfg, func(o *kms.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceKMS)
+	})
+}
+
+// NewSSM creates a new Systems Manager (SSM) client.
+//
+// Returns:
+//   - *ssm.Client: A new SSM client.
+func (a *AWS) NewSSM() *ssm.Client {
+	return ssm.NewFromConfig(a.cfg, func(o *ssm.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceSSM)
+	})
+}
+
+// NewSecretsManager creates a new Secrets Manager client.
+//
+// Returns:
+//   - *secretsmanager.Client: A new Secrets Manager client.
+func (a *AWS) NewSecretsManager() *secretsmanager.Client {
+	return secretsmanager.NewFromConfig(a.cfg, func(o *secretsmanager.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceSecretsManager)
+	})
+}
+
+// NewCloudWatchLogs creates a new CloudWatch Logs client.
+//
+// Returns:
+//   - *cloudwatchlogs.Client: A new CloudWatch Logs client.
+func (a *AWS) NewCloudWatchLogs() *cloudwatchlogs.Client {
+	return cloudwatchlogs.NewFromConfig(a.cfg, func(o *cloudwatchlogs.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceCloudWatchLogs)
+	})
+}
+
+// NewRoute53 creates a new Route 53 client.
+//
+// Returns:
+//   - *route53.Client: A new Route 53 client.
+func (a *AWS) NewRoute53() *route53.Client {
+	return route53.NewFromConfig(a.cfg, func(o *route53.Options) {
+		o.BaseEndpoint = a.endpointFor(ServiceRoute53)
+	})
 }
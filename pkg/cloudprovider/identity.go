@@ -0,0 +1,56 @@
+package cloudprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// CallerIdentity is the identity the AWS clients act as.
+type CallerIdentity struct {
+	// Account is the ID of the AWS account.
+	Account string
+
+	// ARN is the ARN of the user or the assumed role.
+	ARN string
+
+	// UserID is the unique identifier of the user or the role session.
+	UserID string
+}
+
+// String returns a human-readable description of the identity.
+func (i CallerIdentity) String() string {
+	return fmt.Sprintf("account %s as %s", i.Account, i.ARN)
+}
+
+// GetCallerIdentity returns the identity the clients act as, through STS. It is a cheap way to check that the
+// credentials are valid, and to know which account the test will touch before changing anything.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//
+// Returns:
+//   - *CallerIdentity: The identity of the caller.
+//   - error: An error if the identity could not be resolved (e.g.: invalid or expired credentials).
+//
+// Example:
+//
+//	identity, err := aws.GetCallerIdentity(context.TODO())
+//	if err != nil {
+//	    log.Fatalf("Invalid AWS credentials: %v", err)
+//	}
+//	fmt.Printf("Running against %s\n", identity)
+func (a *AWS) GetCallerIdentity(ctx context.Context) (*CallerIdentity, error) {
+	out, err := a.NewSTS().GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the AWS caller identity: %v", err)
+	}
+
+	return &CallerIdentity{
+		Account: aws.ToString(out.Account),
+		ARN:     aws.ToString(out.Arn),
+		UserID:  aws.ToString(out.UserId),
+	}, nil
+}
@@ -0,0 +1,196 @@
+package cloudprovider
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Identifiers of the AWS services whose endpoint can be overridden with WithServiceEndpoints.
+const (
+	ServiceSNS            = "sns"
+	ServiceSQS            = "sqs"
+	ServiceS3             = "s3"
+	ServiceRDS            = "rds"
+	ServiceEC2            = "ec2"
+	ServiceIAM            = "iam"
+	ServiceDynamoDB       = "dynamodb"
+	ServiceAutoScaling    = "autoscaling"
+	ServiceECS            = "ecs"
+	ServiceEKS            = "eks"
+	ServiceSTS            = "sts"
+	ServiceLambda         = "lambda"
+	ServiceKMS            = "kms"
+	ServiceSSM            = "ssm"
+	ServiceSecretsManager = "secretsmanager"
+	ServiceCloudWatchLogs = "logs"
+	ServiceRoute53        = "route53"
+)
+
+// DefaultRoleSessionName is the name of the role session when none is given to WithAssumeRole.
+const DefaultRoleSessionName = "tftest"
+
+// TestCredential is the access key and secret key used against local stand-ins (e.g.: LocalStack, moto), set with
+// WithEndpoint, when no credentials are set in the environment.
+const TestCredential = "test"
+
+// awsOptions represents the configuration options of the AWS Cloud Provider (Client).
+type awsOptions struct {
+	endpoint         string
+	serviceEndpoints map[string]string
+	profile          string
+	assumeRole       *assumeRoleOptions
+}
+
+// assumeRoleOptions represents the role assumed by the AWS Cloud Provider (Client).
+type assumeRoleOptions struct {
+	roleARN     string
+	sessionName string
+	externalID  string
+}
+
+// AWSOptFn is a function type used to modify the options of the AWS Cloud Provider (Client).
+type AWSOptFn func(*awsOptions) error
+
+// WithEndpoint makes every client returned by the AWSAdapter target the given endpoint, instead of the default
+// AWS endpoints. It is typically used with a local stand-in, such as LocalStack or moto.
+//
+// Parameters:
+//   - endpoint: The URL of the endpoint (e.g.: "http://localhost:4566").
+//
+// Returns:
+//   - AWSOptFn: A function to modify the options.
+func WithEndpoint(endpoint string) AWSOptFn {
+	return func(o *awsOptions) error {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+
+		o.endpoint = endpoint
+
+		return nil
+	}
+}
+
+// WithServiceEndpoints makes the clients of the given services target their own endpoint. They take precedence
+// over the endpoint set with WithEndpoint.
+//
+// Parameters:
+//   - endpoints: A map of service identifiers (e.g.: ServiceS3) to the URL of their endpoint.
+//
+// Returns:
+//   - AWSOptFn: A function to modify the options.
+//
+// Example:
+//
+//	aws, err := NewAWS("us-east-1", WithServiceEndpoints(map[string]string{
+//	    ServiceS3:  "http://localhost:4566",
+//	    ServiceSQS: "http://localhost:9324",
+//	}))
+func WithServiceEndpoints(endpoints map[string]string) AWSOptFn {
+	return func(o *awsOptions) error {
+		if o.serviceEndpoints == nil {
+			o.serviceEndpoints = map[string]string{}
+		}
+
+		for service, endpoint := range endpoints {
+			if !isSupportedService(service) {
+				return fmt.Errorf("the service %s is not supported by the AWS Cloud Provider (Client)", service)
+			}
+
+			if err := validateEndpoint(endpoint); err != nil {
+				return err
+			}
+
+			o.serviceEndpoints[service] = endpoint
+		}
+
+		return nil
+	}
+}
+
+// WithProfile loads the configuration and the credentials of the given profile from the shared AWS
+// configuration and credentials files. Profiles configured for SSO or web identity are supported. Terraform
+// only uses the profile through the environment variables returned by GetTerraformEnvVars.
+//
+// Parameters:
+//   - profile: The name of the profile.
+//
+// Returns:
+//   - AWSOptFn: A function to modify the options.
+func WithProfile(profile string) AWSOptFn {
+	return func(o *awsOptions) error {
+		if profile == "" {
+			return fmt.Errorf("the profile name cannot be empty")
+		}
+
+		o.profile = profile
+
+		return nil
+	}
+}
+
+// WithAssumeRole makes the clients assume the given role, using the credentials resolved from the environment
+// or the profile as the source credentials. The role is assumed through the STS endpoint of the clients (see
+// WithServiceEndpoints). Terraform only uses the role through the environment variables returned by
+// GetTerraformEnvVars.
+//
+// Parameters:
+//   - roleARN: The ARN of the role to assume.
+//   - sessionName: The name of the role session. If empty, DefaultRoleSessionName is used.
+//   - externalID: The external ID required by the trust policy of the role, if any.
+//
+// Returns:
+//   - AWSOptFn: A function to modify the options.
+func WithAssumeRole(roleARN, sessionName, externalID string) AWSOptFn {
+	return func(o *awsOptions) error {
+		if !strings.HasPrefix(roleARN, "arn:") {
+			return fmt.Errorf("the role %q is not a valid ARN", roleARN)
+		}
+
+		if sessionName == "" {
+			sessionName = DefaultRoleSessionName
+		}
+
+		o.assumeRole = &assumeRoleOptions{
+			roleARN:     roleARN,
+			sessionName: sessionName,
+			externalID:  externalID,
+		}
+
+		return nil
+	}
+}
+
+// usesTestCredentials reports whether the clients use the test credentials: only when every client targets a
+// custom endpoint (i.e. WithEndpoint is set), and no credentials are set in the environment, nor a profile or a
+// role. The services overridden one by one with WithServiceEndpoints leave the others on AWS, which needs real
+// credentials.
+func (o *awsOptions) usesTestCredentials() bool {
+	hasEnvCredentials := os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != ""
+
+	return o.endpoint != "" && !hasEnvCredentials && o.profile == "" && o.assumeRole == nil
+}
+
+// isSupportedService reports whether the service has a client in the AWSAdapter.
+func isSupportedService(service string) bool {
+	switch service {
+	case ServiceSNS, ServiceSQS, ServiceS3, ServiceRDS, ServiceEC2, ServiceIAM, ServiceDynamoDB,
+		ServiceAutoScaling, ServiceECS, ServiceEKS, ServiceSTS, ServiceLambda, ServiceKMS, ServiceSSM,
+		ServiceSecretsManager, ServiceCloudWatchLogs, ServiceRoute53:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateEndpoint checks that the endpoint is an absolute URL.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("the endpoint %q is not a valid URL (e.g.: http://localhost:4566)", endpoint)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package cloudprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAWSWithEndpoint(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	adapter, err := NewAWS("us-east-1",
+		WithEndpoint("http://localhost:4566"),
+		WithServiceEndpoints(map[string]string{ServiceSQS: "http://localhost:9324", ServiceCloudWatchLogs: "http://localhost:4586"}))
+	require.NoError(t, err)
+
+	s3Options := adapter.NewS3().Options()
+	assert.Equal(t, aws.String("http://localhost:4566"), s3Options.BaseEndpoint)
+	assert.True(t, s3Options.UsePathStyle)
+	assert.Equal(t, aws.String("http://localhost:9324"), adapter.NewSQS().Options().BaseEndpoint)
+	assert.Equal(t, aws.String("http://localhost:4566"), adapter.NewDynamoDB().Options().BaseEndpoint)
+	assert.Equal(t, aws.String("http://localhost:4586"), adapter.NewCloudWatchLogs().Options().BaseEndpoint)
+
+	creds, err := s3Options.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, TestCredential, creds.AccessKeyID)
+}
+
+func TestUsesTestCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	assert.True(t, (&awsOptions{endpoint: "http://localhost:4566"}).usesTestCredentials())
+	assert.False(t, (&awsOptions{serviceEndpoints: map[string]string{ServiceSQS: "http://localhost:9324"}}).usesTestCredentials(),
+		"The services without their own endpoint still target AWS")
+	assert.False(t, (&awsOptions{endpoint: "http://localhost:4566", profile: "sandbox"}).usesTestCredentials())
+	assert.False(t, (&awsOptions{}).usesTestCredentials())
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	assert.False(t, (&awsOptions{endpoint: "http://localhost:4566"}).usesTestCredentials())
+}
+
+func TestGetTerraformEnvVars(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	adapter, err := NewAWS("eu-west-1", WithEndpoint("http://localhost:4566"))
+	require.NoError(t, err)
+
+	envVars, err := adapter.GetTerraformEnvVars(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"AWS_ACCESS_KEY_ID":     TestCredential,
+		"AWS_SECRET_ACCESS_KEY": TestCredential,
+		"AWS_SESSION_TOKEN":     "",
+		"AWS_REGION":            "eu-west-1",
+		"AWS_DEFAULT_REGION":    "eu-west-1",
+	}, envVars)
+}
+
+func TestNewAWSAssumeRoleThroughSTSEndpoint(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var calls atomic.Int32
+
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer sts.Close()
+
+	adapter, err := NewAWS("us-east-1",
+		WithServiceEndpoints(map[string]string{ServiceSTS: sts.URL}),
+		WithAssumeRole("arn:aws:iam::123456789012:role/tests", "", ""))
+	require.NoError(t, err)
+
+	_, err = adapter.NewS3().Options().Credentials.Retrieve(context.Background())
+	assert.Error(t, err)
+	assert.Positive(t, calls.Load(), "The role is assumed through the STS endpoint")
+}
+
+func TestNewAWSWithoutEndpoint(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	adapter, err := NewAWS("us-east-1")
+	require.NoError(t, err)
+
+	s3Options := adapter.NewS3().Options()
+	assert.Nil(t, s3Options.BaseEndpoint)
+	assert.False(t, s3Options.UsePathStyle)
+}
+
+func TestEndpointOptionsValidation(t *testing.T) {
+	_, err := NewAWS("us-east-1", WithEndpoint("localhost:4566"))
+	assert.ErrorContains(t, err, "not a valid URL")
+
+	_, err = NewAWS("us-east-1", WithServiceEndpoints(map[string]string{"glue": "http://localhost:4566"}))
+	assert.ErrorContains(t, err, "not supported")
+}
+
+func TestNewAWSWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile sandbox]\nregion = eu-west-1\n"), 0o600))
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("[sandbox]\naws_access_key_id = AKIASANDBOX\naws_secret_access_key = secret\n"), 0o600))
+
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	adapter, err := NewAWS("", WithProfile("sandbox"))
+	require.NoError(t, err)
+
+	options := adapter.NewS3().Options()
+	assert.Equal(t, "eu-west-1", options.Region)
+
+	creds, err := options.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIASANDBOX", creds.AccessKeyID)
+
+	_, err = NewAWS("", WithProfile("i-do-not-exist"))
+	assert.Error(t, err)
+}
+
+func TestCredentialOptionsValidation(t *testing.T) {
+	assert.Error(t, WithProfile("")(&awsOptions{}))
+	assert.ErrorContains(t, WithAssumeRole("tests", "", "")(&awsOptions{}), "not a valid ARN")
+
+	o := &awsOptions{}
+	require.NoError(t, WithAssumeRole("arn:aws:iam::123456789012:role/tests", "", "external")(o))
+	assert.Equal(t, DefaultRoleSessionName, o.assumeRole.sessionName)
+	assert.Equal(t, "external", o.assumeRole.externalID)
+}
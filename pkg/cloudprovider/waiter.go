@@ -0,0 +1,249 @@
+package cloudprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// Default settings of WaitUntil and Retry.
+const (
+	DefaultWaitInitialInterval = 1 * time.Second
+	DefaultWaitMaxInterval     = 15 * time.Second
+	DefaultWaitMaxDuration     = 2 * time.Minute
+	DefaultWaitMultiplier      = 2.0
+)
+
+// waitOptions represents the polling settings of WaitUntil and Retry.
+type waitOptions struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxDuration     time.Duration
+	multiplier      float64
+	retryableCodes  map[string]bool
+}
+
+// WaitOptFn is a function type used to modify the polling settings of WaitUntil and Retry.
+type WaitOptFn func(*waitOptions) error
+
+// WithInitialInterval sets the time to wait after the first attempt. It grows exponentially after each attempt.
+//
+// Parameters:
+//   - interval: The time to wait after the first attempt.
+//
+// Returns:
+//   - WaitOptFn: A function to modify the options.
+func WithInitialInterval(interval time.Duration) WaitOptFn {
+	return func(o *waitOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("the initial interval must be positive, got %s", interval)
+		}
+
+		o.initialInterval = interval
+
+		return nil
+	}
+}
+
+// WithMaxInterval caps the time to wait between two attempts.
+//
+// Parameters:
+//   - interval: The maximum time to wait between two attempts.
+//
+// Returns:
+//   - WaitOptFn: A function to modify the options.
+func WithMaxInterval(interval time.Duration) WaitOptFn {
+	return func(o *waitOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("the maximum interval must be positive, got %s", interval)
+		}
+
+		o.maxInterval = interval
+
+		return nil
+	}
+}
+
+// WithMaxDuration sets how long to keep polling before giving up.
+//
+// Parameters:
+//   - duration: The maximum duration of the wait.
+//
+// Returns:
+//   - WaitOptFn: A function to modify the options.
+func WithMaxDuration(duration time.Duration) WaitOptFn {
+	return func(o *waitOptions) error {
+		if duration <= 0 {
+			return fmt.Errorf("the maximum duration must be positive, got %s", duration)
+		}
+
+		o.maxDuration = duration
+
+		return nil
+	}
+}
+
+// WithBackoffMultiplier sets the factor applied to the interval after each attempt. 1 polls at a fixed interval.
+//
+// Parameters:
+//   - multiplier: The factor applied to the interval after each attempt.
+//
+// Returns:
+//   - WaitOptFn: A function to modify the options.
+func WithBackoffMultiplier(multiplier float64) WaitOptFn {
+	return func(o *waitOptions) error {
+		if multiplier < 1 {
+			return fmt.Errorf("the backoff multiplier must be at least 1, got %v", multiplier)
+		}
+
+		o.multiplier = multiplier
+
+		return nil
+	}
+}
+
+// WithRetryableErrorCodes makes the AWS errors with the given codes retried instead of ending the wait, typically
+// the "not found" errors returned while a resource created by Terraform is not visible yet.
+//
+// Parameters:
+//   - codes: The AWS error codes (e.g.: "NoSuchBucket", "NoSuchEntity", "ResourceNotFoundException").
+//
+// Returns:
+//   - WaitOptFn: A function to modify the options.
+func WithRetryableErrorCodes(codes ...string) WaitOptFn {
+	return func(o *waitOptions) error {
+		for _, code := range codes {
+			o.retryableCodes[code] = true
+		}
+
+		return nil
+	}
+}
+
+// WaitUntil polls the condition, with an exponential backoff, until it is met, it returns a non-retryable error,
+// the maximum duration is reached or the context is done.
+//
+// Parameters:
+//   - ctx: The context of the wait.
+//   - description: What is waited for, used to prefix the errors (e.g.: "service my-service").
+//   - condition: The condition. It returns true once met, false to poll again, or an error.
+//   - opts: The polling settings.
+//
+// Returns:
+//   - error: An error if the condition is not met in time, or if it returned a non-retryable error.
+//
+// Example:
+//
+//	err := cloudprovider.WaitUntil(ctx, "service my-service", func(ctx context.Context) (bool, error) {
+//	    out, err := client.DescribeServices(ctx, input)
+//	    if err != nil {
+//	        return false, err
+//	    }
+//	    return len(out.Services[0].Deployments) == 1, nil
+//	}, cloudprovider.WithMaxDuration(10*time.Minute))
+func WaitUntil(ctx context.Context, description string, condition func(ctx context.Context) (bool, error), opts ...WaitOptFn) error {
+	_, err := Retry(ctx, description, func(ctx context.Context) (struct{}, error) {
+		done, err := condition(ctx)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if !done {
+			return struct{}{}, errNotReady
+		}
+
+		return struct{}{}, nil
+	}, opts...)
+
+	return err
+}
+
+// Retry calls the function, with an exponential backoff, until it succeeds, it returns a non-retryable error,
+// the maximum duration is reached or the context is done. Only the AWS errors whose code is set with
+// WithRetryableErrorCodes are retried.
+//
+// Parameters:
+//   - ctx: The context of the calls.
+//   - description: What is retried, used to prefix the errors (e.g.: "role my-role").
+//   - fn: The function to call.
+//   - opts: The polling settings.
+//
+// Returns:
+//   - T: The result of the last call.
+//   - error: An error if the function did not succeed in time, or if it returned a non-retryable error. It wraps
+//     the last error of the function, so errors.As reaches the smithy.APIError.
+//
+// Example:
+//
+//	out, err := cloudprovider.Retry(ctx, "role my-role", func(ctx context.Context) (*iam.GetRoleOutput, error) {
+//	    return client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String("my-role")})
+//	}, cloudprovider.WithRetryableErrorCodes("NoSuchEntity"))
+func Retry[T any](ctx context.Context, description string, fn func(ctx context.Context) (T, error), opts ...WaitOptFn) (T, error) {
+	var zero T
+
+	o := &waitOptions{
+		initialInterval: DefaultWaitInitialInterval,
+		maxInterval:     DefaultWaitMaxInterval,
+		maxDuration:     DefaultWaitMaxDuration,
+		multiplier:      DefaultWaitMultiplier,
+		retryableCodes:  map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return zero, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.maxDuration)
+	defer cancel()
+
+	interval := o.initialInterval
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil {
+			return result, nil
+		}
+
+		if !o.isRetryable(err) {
+			return zero, fmt.Errorf("%s: %w", description, err)
+		}
+
+		timer := time.NewTimer(interval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, fmt.Errorf("%s: gave up after %d attempt(s) (%v), last error: %w", description, attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		interval = nextInterval(interval, o.multiplier, o.maxInterval)
+	}
+}
+
+// errNotReady is returned by the condition of WaitUntil when it is not met yet.
+var errNotReady = errors.New("condition not met")
+
+// isRetryable reports whether the error ends the wait or not.
+func (o *waitOptions) isRetryable(err error) bool {
+	if errors.Is(err, errNotReady) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return o.retryableCodes[apiErr.ErrorCode()]
+	}
+
+	return false
+}
+
+// nextInterval returns the interval to wait after the next attempt.
+func nextInterval(interval time.Duration, multiplier float64, maxInterval time.Duration) time.Duration {
+	return min(time.Duration(float64(interval)*multiplier), maxInterval)
+}
@@ -0,0 +1,118 @@
+package cloudprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryRetryableErrorCodes(t *testing.T) {
+	attempts := 0
+
+	out, err := Retry(context.Background(), "bucket my-bucket", func(ctx context.Context) (string, error) {
+		attempts++
+		if attempts < 3 {
+			// The SDK wraps the API errors, as in "operation error S3: HeadBucket, ...".
+			return "", fmt.Errorf("operation error: %w", &smithy.GenericAPIError{Code: "NoSuchBucket"})
+		}
+
+		return "my-bucket", nil
+	}, WithInitialInterval(time.Millisecond), WithRetryableErrorCodes("NoSuchBucket"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "my-bucket", out)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryNonRetryableError(t *testing.T) {
+	attempts := 0
+
+	_, err := Retry(context.Background(), "bucket my-bucket", func(ctx context.Context) (string, error) {
+		attempts++
+		return "", &smithy.GenericAPIError{Code: "AccessDenied", Message: "Access Denied"}
+	}, WithInitialInterval(time.Millisecond), WithRetryableErrorCodes("NoSuchBucket"))
+
+	assert.ErrorContains(t, err, "bucket my-bucket: api error AccessDenied: Access Denied")
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+
+	_, err = Retry(context.Background(), "bucket my-bucket", func(ctx context.Context) (string, error) {
+		attempts++
+		return "", errors.New("connection refused")
+	}, WithInitialInterval(time.Millisecond))
+
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryWrapsTheLastError(t *testing.T) {
+	_, err := Retry(context.Background(), "bucket my-bucket", func(ctx context.Context) (string, error) {
+		return "", &smithy.GenericAPIError{Code: "AccessDenied"}
+	}, WithInitialInterval(time.Millisecond))
+
+	var apiErr smithy.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "AccessDenied", apiErr.ErrorCode())
+
+	_, err = Retry(context.Background(), "bucket my-bucket", func(ctx context.Context) (string, error) {
+		return "", &smithy.GenericAPIError{Code: "NoSuchBucket"}
+	}, WithInitialInterval(time.Millisecond), WithMaxDuration(10*time.Millisecond), WithRetryableErrorCodes("NoSuchBucket"))
+
+	assert.ErrorContains(t, err, "gave up")
+	require.ErrorAs(t, err, &apiErr, "The error of the last attempt is kept once the wait gives up")
+	assert.Equal(t, "NoSuchBucket", apiErr.ErrorCode())
+}
+
+func TestWaitUntil(t *testing.T) {
+	attempts := 0
+
+	err := WaitUntil(context.Background(), "service my-service", func(ctx context.Context) (bool, error) {
+		attempts++
+		return attempts == 4, nil
+	}, WithInitialInterval(time.Millisecond), WithBackoffMultiplier(1))
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, attempts)
+}
+
+func TestWaitUntilMaxDuration(t *testing.T) {
+	err := WaitUntil(context.Background(), "service my-service", func(ctx context.Context) (bool, error) {
+		return false, nil
+	}, WithInitialInterval(5*time.Millisecond), WithMaxDuration(50*time.Millisecond))
+
+	assert.ErrorContains(t, err, "service my-service: gave up after")
+	assert.ErrorContains(t, err, "(context deadline exceeded), last error: condition not met")
+}
+
+func TestWaitUntilContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WaitUntil(ctx, "service my-service", func(ctx context.Context) (bool, error) {
+		return false, nil
+	})
+
+	assert.ErrorContains(t, err, "gave up after 1 attempt(s) (context canceled)")
+}
+
+func TestWaitOptionsValidation(t *testing.T) {
+	condition := func(ctx context.Context) (bool, error) { return true, nil }
+
+	assert.ErrorContains(t, WaitUntil(context.Background(), "x", condition, WithInitialInterval(0)), "must be positive")
+	assert.ErrorContains(t, WaitUntil(context.Background(), "x", condition, WithMaxInterval(-time.Second)), "must be positive")
+	assert.ErrorContains(t, WaitUntil(context.Background(), "x", condition, WithMaxDuration(0)), "must be positive")
+	assert.ErrorContains(t, WaitUntil(context.Background(), "x", condition, WithBackoffMultiplier(0.5)), "at least 1")
+}
+
+func TestNextInterval(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextInterval(time.Second, 2, 15*time.Second))
+	assert.Equal(t, 15*time.Second, nextInterval(10*time.Second, 2, 15*time.Second))
+	assert.Equal(t, time.Second, nextInterval(time.Second, 1, 15*time.Second))
+}
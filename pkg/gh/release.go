@@ -89,3 +89,32 @@ func GetOwnerAndRepoFromURL(repoURL string) (owner, repo string, err error) {
 	}
 	return parts[0], parts[1], nil
 }
+
+// FetchLatestReleaseTag fetches the tag name of the latest GitHub release for a given repository URL.
+//
+// Parameters:
+//   - repoURL: The URL of the GitHub repository (e.g., "https://github.com/owner/repo").
+//
+// Returns:
+//   - string: The tag name of the latest release (e.g., "v1.2.0").
+//   - error: An error if the latest release could not be fetched or has no tag.
+//
+// Example:
+//
+//	tag, err := FetchLatestReleaseTag("https://github.com/owner/repo")
+//	if err != nil {
+//	    log.Fatalf("Error fetching the latest release: %v", err)
+//	}
+//	fmt.Printf("Latest release: %s\n", tag)
+func FetchLatestReleaseTag(repoURL string) (string, error) {
+	releases, err := FetchReleases(repoURL, true)
+	if err != nil {
+		return "", err
+	}
+
+	if len(releases) == 0 || releases[0].GetTagName() == "" {
+		return "", fmt.Errorf("the latest release of %s does not have a tag", repoURL)
+	}
+
+	return releases[0].GetTagName(), nil
+}
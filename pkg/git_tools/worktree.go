@@ -0,0 +1,76 @@
+package git_tools
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AddWorktree checks out the given ref (tag, branch or commit) of the Git repository into the destination directory,
+// as a detached worktree. If the ref is not available locally (e.g.: shallow clones in CI), it tries to fetch it
+// from the 'origin' remote first.
+//
+// Parameters:
+//   - repoRoot: The root of the Git repository.
+//   - ref: The ref to check out.
+//   - dest: The directory where the worktree is created. It must not exist.
+//
+// Returns:
+//   - error: An error if the ref could not be resolved or the worktree could not be created.
+//
+// Example:
+//
+//	err := AddWorktree("/path/to/repo", "v1.2.0", "/tmp/repo-v1.2.0")
+//	if err != nil {
+//	    log.Fatalf("Error checking out the release: %v", err)
+//	}
+func AddWorktree(repoRoot, ref, dest string) error {
+	if repoRoot == "" || ref == "" || dest == "" {
+		return fmt.Errorf("repoRoot, ref and dest are required")
+	}
+
+	if _, err := runGit(repoRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		if _, fetchErr := runGit(repoRoot, "fetch", "--quiet", "origin", ref); fetchErr != nil {
+			return fmt.Errorf("the ref %s does not exist in the repository %s and could not be fetched: %v", ref, repoRoot, fetchErr)
+		}
+
+		ref = "FETCH_HEAD"
+	}
+
+	if out, err := runGit(repoRoot, "worktree", "add", "--detach", dest, ref); err != nil {
+		return fmt.Errorf("failed to check out %s into %s: %v: %s", ref, dest, err, out)
+	}
+
+	return nil
+}
+
+// RemoveWorktree removes a worktree previously created with AddWorktree.
+//
+// Parameters:
+//   - repoRoot: The root of the Git repository.
+//   - dest: The directory of the worktree.
+//
+// Returns:
+//   - error: An error if the worktree could not be removed.
+//
+// Example:
+//
+//	if err := RemoveWorktree("/path/to/repo", "/tmp/repo-v1.2.0"); err != nil {
+//	    log.Printf("Error removing the worktree: %v", err)
+//	}
+func RemoveWorktree(repoRoot, dest string) error {
+	if out, err := runGit(repoRoot, "worktree", "remove", "--force", dest); err != nil {
+		return fmt.Errorf("failed to remove the worktree %s: %v: %s", dest, err, out)
+	}
+
+	return nil
+}
+
+// runGit runs a git command in the given directory and returns its combined output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	return strings.TrimSpace(string(output)), err
+}
@@ -0,0 +1,46 @@
+package git_tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAndRemoveWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoRoot := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoRoot
+		out, err := cmd.CombinedOutput()
+		require.NoErrorf(t, err, "git %v failed: %s", args, out)
+	}
+
+	gitCmd("init", "--quiet")
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "main.tf"), []byte("# v1\n"), 0o600))
+	gitCmd("add", "main.tf")
+	gitCmd("commit", "--quiet", "-m", "v1")
+	gitCmd("tag", "v1.0.0")
+	require.NoError(t, os.WriteFile(filepath.Join(repoRoot, "main.tf"), []byte("# v2\n"), 0o600))
+	gitCmd("commit", "--quiet", "-am", "v2")
+
+	dest := filepath.Join(t.TempDir(), "v1")
+	require.NoError(t, AddWorktree(repoRoot, "v1.0.0", dest))
+
+	content, err := os.ReadFile(filepath.Join(dest, "main.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# v1\n", string(content))
+
+	assert.NoError(t, RemoveWorktree(repoRoot, dest))
+	assert.NoDirExists(t, dest)
+
+	assert.Error(t, AddWorktree(repoRoot, "v9.9.9", filepath.Join(t.TempDir(), "v9")))
+	assert.Error(t, AddWorktree("", "v1.0.0", dest))
+}
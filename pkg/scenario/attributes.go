@@ -0,0 +1,120 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitAttributePath splits an attribute path into its segments. Both the dotted form (e.g.: "versioning.0.enabled")
+// and the index form (e.g.: "versioning[0].enabled") are supported.
+//
+// Parameters:
+//   - path: The attribute path.
+//
+// Returns:
+//   - []string: The segments of the path.
+func splitAttributePath(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+// lookupAttribute returns the value found at the given attribute path, walking nested objects and lists.
+//
+// Parameters:
+//   - value: The value to walk (e.g.: the 'after' value of a resource change).
+//   - path: The attribute path (e.g.: "versioning.0.enabled").
+//
+// Returns:
+//   - interface{}: The value found at the path.
+//   - bool: True if the path exists in the value.
+func lookupAttribute(value interface{}, path string) (interface{}, bool) {
+	current := value
+
+	for _, segment := range splitAttributePath(path) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			nested, exists := v[segment]
+			if !exists {
+				return nil, false
+			}
+
+			current = nested
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// isAttributeMarked reports whether the attribute at the given path is marked in a marks structure, such as the
+// 'after_unknown' or 'after_sensitive' values of a change. A mark on a parent (e.g.: a whole block known only
+// after apply) also applies to its nested attributes.
+//
+// Parameters:
+//   - marks: The marks structure.
+//   - path: The attribute path.
+//
+// Returns:
+//   - bool: True if the attribute, or any of its parents, is marked.
+func isAttributeMarked(marks interface{}, path string) bool {
+	current := marks
+
+	for _, segment := range splitAttributePath(path) {
+		if b, ok := current.(bool); ok {
+			return b
+		}
+
+		nested, exists := lookupAttribute(current, segment)
+		if !exists {
+			return false
+		}
+
+		current = nested
+	}
+
+	b, ok := current.(bool)
+
+	return ok && b
+}
+
+// normalizeJSONValue converts a Go value into its JSON representation (maps, slices, float64, string, bool, nil),
+// so it can be deeply compared with the values parsed from the Terraform JSON output.
+//
+// Parameters:
+//   - value: The value to normalize.
+//
+// Returns:
+//   - interface{}: The normalized value.
+//   - error: An error if the value could not be converted.
+func normalizeJSONValue(value interface{}) (interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value %v: %v", value, err)
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value %v: %v", value, err)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupAttribute(t *testing.T) {
+	value := map[string]interface{}{
+		"bucket": "my-bucket",
+		"versioning": []interface{}{
+			map[string]interface{}{"enabled": true},
+		},
+		"tags": map[string]interface{}{"Name": "test"},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{"Top level attribute", "bucket", "my-bucket", true},
+		{"Nested block with dotted index", "versioning.0.enabled", true, true},
+		{"Nested block with bracket index", "versioning[0].enabled", true, true},
+		{"Map key", "tags.Name", "test", true},
+		{"Missing attribute", "acl", nil, false},
+		{"Index out of range", "versioning.1.enabled", nil, false},
+		{"Invalid index", "versioning.first", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, found := lookupAttribute(value, tt.path)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestIsAttributeMarked(t *testing.T) {
+	marks := map[string]interface{}{
+		"arn":        true,
+		"versioning": []interface{}{map[string]interface{}{"mfa_delete": true}},
+		"logging":    true,
+		"bucket":     false,
+	}
+
+	assert.True(t, isAttributeMarked(marks, "arn"))
+	assert.True(t, isAttributeMarked(marks, "versioning.0.mfa_delete"))
+	assert.True(t, isAttributeMarked(marks, "logging.0.target_bucket"))
+	assert.False(t, isAttributeMarked(marks, "bucket"))
+	assert.False(t, isAttributeMarked(marks, "tags"))
+	assert.False(t, isAttributeMarked(nil, "arn"))
+}
+
+func TestNormalizeJSONValue(t *testing.T) {
+	normalized, err := normalizeJSONValue(map[string]interface{}{"count": 2, "names": []string{"a"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"count": float64(2), "names": []interface{}{"a"}}, normalized)
+}
+
+func TestPlanResourceAttributeAssertions(t *testing.T) {
+	plan, err := NewPlanFromJSON(testPlanJSON)
+	assert.NoError(t, err)
+
+	plan.AssertResourceAttributeBefore(t, "aws_s3_bucket.this", "tags.owner", "a")
+	plan.AssertResourceAttributeAfter(t, "aws_s3_bucket.this", "tags", map[string]string{"owner": "b"})
+	plan.AssertResourceAttributeAfter(t, "random_id.this", "byte_length", 8)
+	plan.AssertResourceAttributeChanged(t, "aws_s3_bucket.this", "tags.owner")
+	plan.AssertResourceAttributeUnknown(t, "aws_s3_bucket.this", "arn")
+	plan.AssertResourceAttributeKnown(t, "aws_s3_bucket.this", "bucket")
+	plan.AssertResourceAttributeSensitive(t, "aws_s3_bucket.this", "secret")
+}
@@ -0,0 +1,155 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// noLocationFile groups the diagnostics that are not attached to any file.
+const noLocationFile = "(no file)"
+
+// ValidateStage initializes the Terraform stage without its backend, and runs 'terraform validate -json' on it.
+// If the configuration is not valid, the test fails with the errors grouped by file.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - Diagnostics: The warnings reported by validate.
+//
+// Example:
+//
+//	s.Stg.ValidateStage(t, s.GetTerraformOptions()).AssertNoWarnings(t)
+func (c *StageClient) ValidateStage(t *testing.T, options *terraform.Options) Diagnostics {
+	out, err := terraform.RunTerraformCommandE(t, options, "init", "-backend=false", "-input=false", "-no-color")
+	require.NoErrorf(t, err, "Failed to init terraform: %s", out)
+
+	out, err = terraform.RunTerraformCommandE(t, options, "validate", "-json", "-no-color")
+	diagnostics := ParseDiagnostics(out)
+
+	if errs := diagnostics.Errors(); len(errs) > 0 {
+		require.Failf(t, "The configuration is not valid", "%d error(s):\n%s", len(errs), groupDiagnosticsByFile(errs))
+	}
+
+	require.NoErrorf(t, err, "Failed to validate terraform: %s", out)
+
+	return diagnostics
+}
+
+// FmtCheckStage runs 'terraform fmt -check -diff -recursive' on the Terraform stage. If some files are not
+// formatted, the test fails with the list of files and the unified diff. The override files generated by tftest
+// (see WithOverrideFile and WithBackendOverride) are not part of the module, so they are not reported.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Example:
+//
+//	s.Stg.FmtCheckStage(t, s.GetTerraformOptions())
+func (c *StageClient) FmtCheckStage(t *testing.T, options *terraform.Options) {
+	out, err := terraform.RunTerraformCommandE(t, options, "fmt", "-check", "-diff", "-recursive", "-no-color")
+	if err == nil {
+		return
+	}
+
+	diffs := parseFmtDiffs(out)
+	require.NotEmptyf(t, diffs, "Failed to check the format of the terraform files: %s", out)
+
+	files := make([]string, 0, len(diffs))
+	report := make([]string, 0, len(diffs))
+
+	for _, diff := range diffs {
+		if _, generated := c.generatedFiles[diff.file]; generated {
+			continue
+		}
+
+		files = append(files, diff.file)
+		report = append(report, diff.diff)
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	require.Failf(t, "Some files are not formatted",
+		"Run 'terraform fmt -recursive' in %s to fix:\n  - %s\n\n%s", options.TerraformDir, strings.Join(files, "\n  - "), strings.Join(report, "\n"))
+}
+
+// groupDiagnosticsByFile returns a report of the diagnostics grouped by the file they are attached to, with the
+// files sorted by name.
+func groupDiagnosticsByFile(diagnostics Diagnostics) string {
+	byFile := map[string]Diagnostics{}
+
+	for _, diagnostic := range diagnostics {
+		file := noLocationFile
+		if diagnostic.Range != nil && diagnostic.Range.Filename != "" {
+			file = diagnostic.Range.Filename
+		}
+
+		byFile[file] = append(byFile[file], diagnostic)
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+
+	report := make([]string, 0, len(files))
+	for _, file := range files {
+		report = append(report, fmt.Sprintf("%s:\n%s", file, byFile[file]))
+	}
+
+	return strings.Join(report, "\n")
+}
+
+// fmtDiff is the diff reported by 'terraform fmt -check -diff' for a file.
+type fmtDiff struct {
+	file string
+	diff string
+}
+
+// parseFmtDiffs splits the output of 'terraform fmt -check -diff' into the diff of each file. Each diff starts
+// with the "--- old/<file>" header, preceded by the name of the file.
+func parseFmtDiffs(output string) []fmtDiff {
+	var (
+		diffs  []fmtDiff
+		starts []int
+	)
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+	for i, line := range lines {
+		file, found := strings.CutPrefix(strings.TrimSpace(line), "--- old/")
+		if !found {
+			continue
+		}
+
+		start := i
+		if i > 0 && strings.TrimSpace(lines[i-1]) == file {
+			start = i - 1
+		}
+
+		diffs = append(diffs, fmtDiff{file: file})
+		starts = append(starts, start)
+	}
+
+	for i := range diffs {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+
+		diffs[i].diff = strings.Join(lines[starts[i]:end], "\n")
+	}
+
+	return diffs
+}
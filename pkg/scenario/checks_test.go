@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDiagnosticsByFile(t *testing.T) {
+	diagnostics := Diagnostics{
+		{Severity: tfjson.DiagnosticSeverityError, Summary: "Unsupported argument", Range: &tfjson.Range{Filename: "variables.tf", Start: tfjson.Pos{Line: 4, Column: 3}}},
+		{Severity: tfjson.DiagnosticSeverityError, Summary: "Missing required argument", Range: &tfjson.Range{Filename: "main.tf", Start: tfjson.Pos{Line: 1, Column: 1}}},
+		{Severity: tfjson.DiagnosticSeverityError, Summary: "Invalid reference", Range: &tfjson.Range{Filename: "main.tf", Start: tfjson.Pos{Line: 9, Column: 5}}},
+		{Severity: tfjson.DiagnosticSeverityError, Summary: "Module not installed"},
+	}
+
+	expected := `(no file):
+  - error: Module not installed
+main.tf:
+  - error: Missing required argument
+    at main.tf:1,1
+  - error: Invalid reference
+    at main.tf:9,5
+variables.tf:
+  - error: Unsupported argument
+    at variables.tf:4,3`
+
+	assert.Equal(t, expected, groupDiagnosticsByFile(diagnostics))
+}
+
+func TestParseFmtDiffsOfNestedFiles(t *testing.T) {
+	output := `main.tf
+--- old/main.tf
++++ new/main.tf
+@@ -1,3 +1,3 @@
+ resource "random_id" "this" {
+-  byte_length= 8
++  byte_length = 8
+ }
+modules/child/variables.tf
+--- old/modules/child/variables.tf
++++ new/modules/child/variables.tf
+@@ -1 +1 @@
+-variable "name" {   }
++variable "name" {}
+`
+
+	var files []string
+	for _, diff := range parseFmtDiffs(output) {
+		files = append(files, diff.file)
+	}
+
+	assert.Equal(t, []string{"main.tf", "modules/child/variables.tf"}, files)
+	assert.Empty(t, parseFmtDiffs(""))
+}
+
+func TestParseFmtDiffs(t *testing.T) {
+	output := `main.tf
+--- old/main.tf
++++ new/main.tf
+@@ -1 +1 @@
+-locals {   }
++locals {}
+tftest_backend_override.tf
+--- old/tftest_backend_override.tf
++++ new/tftest_backend_override.tf
+@@ -1 +1 @@
+-terraform {   }
++terraform {}
+`
+
+	diffs := parseFmtDiffs(output)
+	assert.Equal(t, []fmtDiff{
+		{file: "main.tf", diff: "main.tf\n--- old/main.tf\n+++ new/main.tf\n@@ -1 +1 @@\n-locals {   }\n+locals {}"},
+		{file: "tftest_backend_override.tf", diff: "tftest_backend_override.tf\n--- old/tftest_backend_override.tf\n+++ new/tftest_backend_override.tf\n@@ -1 +1 @@\n-terraform {   }\n+terraform {}"},
+	}, diffs)
+	assert.Empty(t, parseFmtDiffs("Error: Failed to read file"))
+}
@@ -1,6 +1,7 @@
 package scenario
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -15,14 +16,22 @@ import (
 
 // Options represents the configuration options for a Terraform scenario.
 type Options struct {
-	vars         map[string]interface{}
-	varFiles     []string
-	enableAWS    bool
-	awsRegion    string
-	isParallel   bool
-	retryOptions *retryableOptions
-	envVars      map[string]string
-	planFile     string
+	vars             map[string]interface{}
+	varFiles         []string
+	enableAWS        bool
+	awsRegion        string
+	awsOpts          []cloudprovider.AWSOptFn
+	awsIdentityCheck bool
+	allowedAccounts  []string
+	allowedRegions   []string
+	isParallel       bool
+	retryOptions     *retryableOptions
+	envVars          map[string]string
+	planFile         string
+	isTerragrunt     bool
+	workspace        string
+	backend          *backendOverride
+	overrideFiles    []overrideFile
 }
 
 // retryableOptions represents the retry options for Terraform operations.
@@ -37,10 +46,13 @@ type OptFn func(*Options) error
 
 // Client represents a Terraform client for managing Terraform operations.
 type Client struct {
-	t        *testing.T
-	opts     *terraform.Options
-	Stg      *StageClient
-	awsCloud cloudprovider.AWSAdapter
+	t             *testing.T
+	opts          *terraform.Options
+	Stg           *StageClient
+	awsCloud      cloudprovider.AWSAdapter
+	workspace     string
+	stateFilePath string
+	isolated      bool
 }
 
 // Config defines an interface for obtaining Terraform options and AWS configuration.
@@ -98,7 +110,9 @@ func WithPlanFile(planFile string) OptFn {
 	}
 }
 
-// WithAWS enables the AWS Cloud Provider (Client) for the options and sets the AWS region.
+// WithAWS enables the AWS Cloud Provider (Client) for the options and sets the AWS region. Terraform runs with the
+// credentials and the region of the client (see cloudprovider.AWS.GetTerraformEnvVars), unless they are
+// overridden with WithEnvVars.
 //
 // Parameters:
 //   - region: The AWS region. If not set, it defaults to "us-west-2".
@@ -118,6 +132,40 @@ func WithAWS(region string) OptFn {
 	}
 }
 
+// WithAWSOptions sets the options of the AWS Cloud Provider (Client), such as custom endpoints for local
+// stand-ins. It requires WithAWS.
+//
+// Parameters:
+//   - opts: A list of option functions to modify the AWS options.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+//
+// Example:
+//
+//	s, err := scenario.NewWithOptions(t, workdir,
+//	    scenario.WithAWS("us-east-1"),
+//	    scenario.WithAWSOptions(cloudprovider.WithEndpoint("http://localhost:4566")))
+func WithAWSOptions(opts ...cloudprovider.AWSOptFn) OptFn {
+	return func(o *Options) error {
+		o.awsOpts = append(o.awsOpts, opts...)
+		return nil
+	}
+}
+
+// WithAWSIdentityCheck resolves the AWS identity (account and ARN) through STS when the scenario is created, and
+// logs it, so it is clear which account the test will touch. The scenario is not created if the credentials are
+// not valid. It requires WithAWS.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithAWSIdentityCheck() OptFn {
+	return func(o *Options) error {
+		o.awsIdentityCheck = true
+		return nil
+	}
+}
+
 // WithRetry sets the retry options for the Terraform operations.
 //
 // Parameters:
@@ -150,6 +198,19 @@ func WithParallel() OptFn {
 	}
 }
 
+// WithTerragrunt makes the scenario drive Terragrunt instead of Terraform.
+// The working directory can be either a single Terragrunt module or a Terragrunt stack; for the latter,
+// use the TerragruntRunAll* stages of the StageClient.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithTerragrunt() OptFn {
+	return func(o *Options) error {
+		o.isTerragrunt = true
+		return nil
+	}
+}
+
 // WithEnvVars sets the environment variables for the options.
 //
 // Parameters:
@@ -253,30 +314,78 @@ func NewWithOptions(t *testing.T, workdir string, opts ...OptFn) (*Client, error
 		}
 	}
 
-	tfDir, err := GetTerraformDir(t, workdir, o.isParallel)
+	var tfDir string
+	var err error
+
+	// The generated override files must never be written into the module sources, so the scenario works on a
+	// copy of the module when it needs them.
+	isolated := o.isParallel || o.hasOverrideFiles()
+	if isolated && !o.isParallel {
+		t.Logf("Copying %s to a temporary directory, so the override files do not touch its sources", workdir)
+	}
+
+	if o.isTerragrunt {
+		tfDir, err = GetTerragruntDir(t, workdir, isolated)
+	} else {
+		tfDir, err = GetTerraformDir(t, workdir, isolated)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	c := &Client{}
+	c := &Client{
+		t:        t,
+		Stg:      &StageClient{},
+		isolated: isolated && !isSameDir(workdir, tfDir),
+	}
+
+	if o.hasOverrideFiles() && !c.isolated {
+		return nil, fmt.Errorf("the module %s could not be copied to a temporary directory (is a SKIP_* variable set?), "+
+			"and the override files cannot be written into its sources", workdir)
+	}
 
 	tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		TerraformDir: tfDir,
 		NoColor:      true,
 	})
 
+	if o.isTerragrunt {
+		t.Logf("Enabling Terragrunt as the binary for the scenario in: %s", tfDir)
+		tfOptions.TerraformBinary = TerragruntBinary
+	}
+
 	if o.planFile != "" {
 		tfOptions.PlanFilePath = filepath.Join(tfDir, o.planFile)
+
+		// Terragrunt runs Terraform from its cache directory, so relative plan paths would not resolve.
+		if o.isTerragrunt {
+			absPlanFile, absErr := filepath.Abs(tfOptions.PlanFilePath)
+			if absErr != nil {
+				return nil, fmt.Errorf("failed to resolve the absolute path of the plan file %s: %v", tfOptions.PlanFilePath, absErr)
+			}
+
+			tfOptions.PlanFilePath = absPlanFile
+		}
 	}
 
 	if o.enableAWS {
-		cfg, err := cloudprovider.NewAWS(o.awsRegion)
+		cfg, err := cloudprovider.NewAWS(o.awsRegion, o.awsOpts...)
 		t.Logf("Enabling AWS Cloud Provider (Client) with region: %s", o.awsRegion)
 		if err != nil {
 			return nil, err
 		}
 
 		c.awsCloud = cfg
+
+		if o.awsIdentityCheck {
+			identity, err := cfg.GetCallerIdentity(context.TODO())
+			if err != nil {
+				return nil, err
+			}
+
+			t.Logf("The scenario will run against AWS %s, in region %s", identity, o.awsRegion)
+		}
 	}
 
 	if len(o.vars) > 0 {
@@ -295,6 +404,73 @@ func NewWithOptions(t *testing.T, workdir string, opts ...OptFn) (*Client, error
 		tfOptions.MaxRetries = o.retryOptions.maxRetries
 	}
 
+	tfOptions.EnvVars = map[string]string{}
+
+	var awsCredentials map[string]string
+
+	if c.awsCloud != nil {
+		// Terraform runs with the same credentials and region as the AWS clients, e.g. a profile or an assumed role.
+		awsEnvVars, err := c.awsCloud.GetTerraformEnvVars(context.TODO())
+		if err != nil {
+			t.Logf("Terraform resolves its own AWS credentials: %v", err)
+			awsEnvVars = map[string]string{"AWS_REGION": c.awsCloud.GetRegion(), "AWS_DEFAULT_REGION": c.awsCloud.GetRegion()}
+		} else {
+			awsCredentials = awsEnvVars
+		}
+
+		for key, value := range awsEnvVars {
+			tfOptions.EnvVars[key] = value
+		}
+	}
+
+	for key, value := range o.envVars {
+		tfOptions.EnvVars[key] = value
+	}
+
+	guard, err := newAWSGuard(t, o, c.awsCloud, awsCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Stg.guard = guard
+
+	if o.backend != nil {
+		t.Logf("Forcing the %s backend on the scenario in: %s", o.backend.backendType, tfDir)
+
+		content, err := renderBackendOverride(o.backend.backendType, o.backend.config)
+		if err != nil {
+			return nil, err
+		}
+
+		o.overrideFiles = append(o.overrideFiles, overrideFile{name: BackendOverrideFile, content: content})
+		c.Stg.backend = o.backend
+
+		// The module may have been initialized with its own backend before.
+		tfOptions.Reconfigure = true
+	}
+
+	for _, file := range o.overrideFiles {
+		if err := writeOverrideFile(t, tfDir, file); err != nil {
+			return nil, err
+		}
+
+		c.Stg.trackGeneratedFile(file)
+	}
+
+	if o.workspace != "" {
+		t.Logf("Selecting the Terraform workspace: %s", o.workspace)
+
+		if err := setupWorkspace(t, tfOptions, o.workspace); err != nil {
+			return nil, err
+		}
+
+		c.workspace = o.workspace
+	}
+
+	if o.backend != nil && o.backend.backendType == LocalBackend {
+		c.stateFilePath = localStatePath(tfDir, o.backend.config, o.workspace)
+	}
+
 	c.opts = tfOptions
 
 	return c, nil
@@ -320,9 +496,28 @@ func New(t *testing.T, workdir string) (*Client, error) {
 		NoColor:      true,
 	})
 
+	guard, err := newAWSGuard(t, &Options{}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
 		t:    t,
 		opts: terraformOptions,
-		Stg:  &StageClient{},
+		Stg:  &StageClient{guard: guard},
 	}, nil
 }
+
+// NewTerragrunt creates a new Terragrunt Client with default retryable errors.
+// The workdir can be either a single Terragrunt module or a Terragrunt stack.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - workdir: The working directory.
+//
+// Returns:
+//   - *Client: A new Client instance.
+//   - error: An error if the Client could not be created.
+func NewTerragrunt(t *testing.T, workdir string) (*Client, error) {
+	return NewWithOptions(t, workdir, WithTerragrunt())
+}
@@ -1,3 +1,43 @@
 package scenario
 
 const DefaultPlanOutput = "plan.out"
+
+// TerragruntBinary is the name of the binary used when the scenario is driven by Terragrunt.
+const TerragruntBinary = "terragrunt"
+
+// SnapshotUpdateEnvVar is the environment variable that, when set to "1" or "true", makes the snapshot
+// assertions rewrite the golden files instead of comparing against them.
+const SnapshotUpdateEnvVar = "UPDATE_SNAPSHOTS"
+
+// DefaultSnapshotDir is the directory, relative to the test package, where the plan snapshots are stored.
+const DefaultSnapshotDir = "testdata/snapshots"
+
+// WorkspaceEnvVar is the environment variable that selects the Terraform workspace without touching the
+// working directory, so several scenarios can share it.
+const WorkspaceEnvVar = "TF_WORKSPACE"
+
+// DataDirEnvVar is the environment variable that moves the data directory of Terraform, .terraform by default.
+const DataDirEnvVar = "TF_DATA_DIR"
+
+// DefaultWorkspace is the workspace Terraform uses when none is selected. It cannot be deleted.
+const DefaultWorkspace = "default"
+
+// LocalBackend is the type of the Terraform backend that keeps the state in the working directory.
+const LocalBackend = "local"
+
+// BackendOverrideFile is the name of the override file generated to force a backend on the scenario.
+const BackendOverrideFile = "tftest_backend_override.tf"
+
+// generatedFileHeader is the first line of the files written by tftest into the working directory, so the ones
+// left behind by a run that did not finish can be told apart from the files of the module.
+const generatedFileHeader = "# Generated by tftest, removed once the test finishes.\n"
+
+// AWSProviderOverrideFile is the name of the override file that points the AWS provider at a local stand-in.
+const AWSProviderOverrideFile = "tftest_aws_provider_override.tf"
+
+// AWSTestCredential is the fake access key and secret key used when the AWS provider points at a local stand-in.
+const AWSTestCredential = "test"
+
+// AllowedAccountsEnvVar is the environment variable that lists, comma-separated, the AWS accounts where the
+// scenarios are allowed to apply.
+const AllowedAccountsEnvVar = "TFTEST_ALLOWED_ACCOUNTS"
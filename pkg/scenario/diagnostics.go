@@ -0,0 +1,339 @@
+package scenario
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Diagnostic is an error or a warning reported by terraform.
+type Diagnostic struct {
+	// Severity is either tfjson.DiagnosticSeverityError or tfjson.DiagnosticSeverityWarning.
+	Severity tfjson.DiagnosticSeverity `json:"severity"`
+
+	// Summary is the short description of the diagnostic (e.g.: "Invalid value for variable").
+	Summary string `json:"summary"`
+
+	// Detail is the long description of the diagnostic.
+	Detail string `json:"detail,omitempty"`
+
+	// Address is the address of the resource the diagnostic refers to, if any.
+	Address string `json:"address,omitempty"`
+
+	// Range is the location of the diagnostic in the configuration, if any.
+	Range *tfjson.Range `json:"range,omitempty"`
+
+	// Snippet is the source code around Range, with the values of the expressions involved.
+	Snippet *tfjson.DiagnosticSnippet `json:"snippet,omitempty"`
+}
+
+// String returns a human-readable description of the diagnostic, on one or more lines.
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s: %s", d.Severity, d.Summary)
+
+	if d.Address != "" {
+		fmt.Fprintf(&sb, " (%s)", d.Address)
+	}
+
+	if d.Range != nil {
+		fmt.Fprintf(&sb, "\n    at %s:%d,%d", d.Range.Filename, d.Range.Start.Line, d.Range.Start.Column)
+	}
+
+	if d.Snippet != nil {
+		for _, value := range d.Snippet.Values {
+			fmt.Fprintf(&sb, "\n    %s %s", value.Traversal, value.Statement)
+		}
+	}
+
+	if d.Detail != "" {
+		fmt.Fprintf(&sb, "\n    %s", strings.ReplaceAll(d.Detail, "\n", "\n    "))
+	}
+
+	return sb.String()
+}
+
+// Diagnostics is a list of diagnostics reported by a single terraform command.
+type Diagnostics []Diagnostic
+
+// Errors returns the diagnostics with the error severity.
+func (d Diagnostics) Errors() Diagnostics {
+	return d.withSeverity(tfjson.DiagnosticSeverityError)
+}
+
+// Warnings returns the diagnostics with the warning severity.
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.withSeverity(tfjson.DiagnosticSeverityWarning)
+}
+
+// String returns a report with one entry per diagnostic.
+func (d Diagnostics) String() string {
+	report := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		report = append(report, "  - "+diagnostic.String())
+	}
+
+	return strings.Join(report, "\n")
+}
+
+// Matching returns the diagnostics whose summary or detail match the given regular expression.
+//
+// Parameters:
+//   - pattern: The regular expression to match.
+//
+// Returns:
+//   - Diagnostics: The matching diagnostics.
+//   - error: An error if the pattern is not a valid regular expression.
+func (d Diagnostics) Matching(pattern string) (Diagnostics, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+
+	var matching Diagnostics
+
+	for _, diagnostic := range d {
+		if re.MatchString(diagnostic.Summary) || re.MatchString(diagnostic.Detail) {
+			matching = append(matching, diagnostic)
+		}
+	}
+
+	return matching, nil
+}
+
+// AssertNoErrors checks that there are no error diagnostics.
+//
+// Parameters:
+//   - t: The testing instance.
+func (d Diagnostics) AssertNoErrors(t *testing.T) {
+	errs := d.Errors()
+	assert.Emptyf(t, errs, "Terraform reported %d error(s):\n%s", len(errs), errs)
+}
+
+// AssertNoWarnings checks that there are no warning diagnostics.
+//
+// Parameters:
+//   - t: The testing instance.
+func (d Diagnostics) AssertNoWarnings(t *testing.T) {
+	warnings := d.Warnings()
+	assert.Emptyf(t, warnings, "Terraform reported %d warning(s):\n%s", len(warnings), warnings)
+}
+
+// AssertNoWarningMatching checks that no warning has a summary or a detail that matches the given regular
+// expression (e.g.: "(?i)deprecated").
+//
+// Parameters:
+//   - t: The testing instance.
+//   - pattern: The regular expression to match.
+func (d Diagnostics) AssertNoWarningMatching(t *testing.T, pattern string) {
+	warnings, err := d.Warnings().Matching(pattern)
+	require.NoErrorf(t, err, "Failed to match the warnings")
+
+	assert.Emptyf(t, warnings, "Terraform reported %d warning(s) matching %q:\n%s", len(warnings), pattern, warnings)
+}
+
+// AssertWarningMatching checks that at least one warning has a summary or a detail that matches the given
+// regular expression.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - pattern: The regular expression to match.
+func (d Diagnostics) AssertWarningMatching(t *testing.T, pattern string) {
+	warnings, err := d.Warnings().Matching(pattern)
+	require.NoErrorf(t, err, "Failed to match the warnings")
+
+	assert.NotEmptyf(t, warnings, "No warning matches %q, found:\n%s", pattern, d.Warnings())
+}
+
+func (d Diagnostics) withSeverity(severity tfjson.DiagnosticSeverity) Diagnostics {
+	var filtered Diagnostics
+
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+
+	return filtered
+}
+
+var (
+	ansiEscapePattern    = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	errorSummaryPattern  = regexp.MustCompile(`^Error: (.*)$`)
+	warningHeaderPattern = regexp.MustCompile(`^Warning: `)
+)
+
+// uiMessage is a line of the machine-readable UI stream written by 'terraform plan -json' and 'terraform apply -json'.
+type uiMessage struct {
+	Type       string      `json:"type"`
+	Diagnostic *Diagnostic `json:"diagnostic"`
+}
+
+// validateOutput is the document written by 'terraform validate -json'. tfjson.ValidateOutput is not used because
+// its diagnostics do not carry the address.
+type validateOutput struct {
+	FormatVersion string       `json:"format_version"`
+	Diagnostics   []Diagnostic `json:"diagnostics"`
+}
+
+// ParseDiagnostics extracts the diagnostics from the output of a terraform command. It understands the
+// machine-readable UI stream of 'plan -json' and 'apply -json', the document of 'validate -json', and, when none
+// of them is found (e.g.: an error raised before terraform switched to JSON), the human-readable error blocks.
+//
+// Parameters:
+//   - output: The output of the terraform command.
+//
+// Returns:
+//   - Diagnostics: The diagnostics, in the order terraform reported them.
+//
+// Example:
+//
+//	diagnostics := ParseDiagnostics(out)
+//	for _, diagnostic := range diagnostics.Errors() {
+//	    fmt.Println(diagnostic.Summary)
+//	}
+func ParseDiagnostics(output string) Diagnostics {
+	var (
+		diagnostics Diagnostics
+		isJSON      bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var message uiMessage
+		if err := json.Unmarshal([]byte(line), &message); err == nil && message.Type != "" {
+			isJSON = true
+
+			if message.Type == "diagnostic" && message.Diagnostic != nil {
+				diagnostics = append(diagnostics, *message.Diagnostic)
+			}
+		}
+	}
+
+	if isJSON {
+		return diagnostics
+	}
+
+	// 'terraform validate -json' pretty-prints a single document on several lines.
+	start, end := strings.Index(output, "{"), strings.LastIndex(output, "}")
+	if start >= 0 && end > start {
+		var validation validateOutput
+		if err := json.Unmarshal([]byte(output[start:end+1]), &validation); err == nil && validation.FormatVersion != "" {
+			return validation.Diagnostics
+		}
+	}
+
+	return parseHumanReadableErrors(output)
+}
+
+// parseHumanReadableErrors extracts the error blocks from the human-readable output of terraform. Each block
+// starts with an "Error: <summary>" line and ends with the next error or warning, or with the end of the box that
+// terraform draws around diagnostics. The location and the source snippet are kept in the detail.
+func parseHumanReadableErrors(output string) Diagnostics {
+	var (
+		errs    Diagnostics
+		current *Diagnostic
+		detail  []string
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Detail = strings.TrimSpace(strings.Join(detail, "\n"))
+			errs = append(errs, *current)
+		}
+
+		current, detail = nil, nil
+	}
+
+	for _, line := range strings.Split(ansiEscapePattern.ReplaceAllString(output, ""), "\n") {
+		line = strings.TrimRight(line, " \r")
+
+		switch {
+		case strings.HasPrefix(line, "╵"):
+			flush()
+			continue
+		case strings.HasPrefix(line, "╷"):
+			continue
+		}
+
+		line = strings.TrimPrefix(strings.TrimPrefix(line, "│"), " ")
+
+		if match := errorSummaryPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			current = &Diagnostic{Severity: tfjson.DiagnosticSeverityError, Summary: strings.TrimSpace(match[1])}
+
+			continue
+		}
+
+		if warningHeaderPattern.MatchString(line) {
+			flush()
+			continue
+		}
+
+		if current != nil {
+			detail = append(detail, line)
+		}
+	}
+
+	flush()
+
+	return errs
+}
+
+// requireNoDiagnosticErrors fails the test if the terraform command failed, with one entry per error diagnostic.
+// If no error diagnostic could be parsed, the whole output is reported instead.
+func requireNoDiagnosticErrors(t *testing.T, command string, diagnostics Diagnostics, out string, err error) Diagnostics {
+	if err == nil {
+		return diagnostics
+	}
+
+	if errs := diagnostics.Errors(); len(errs) > 0 {
+		require.Failf(t, fmt.Sprintf("Failed to %s terraform", command), "%d error(s):\n%s", len(errs), errs)
+	}
+
+	require.NoErrorf(t, err, "Failed to %s terraform: %s", command, out)
+
+	return diagnostics
+}
+
+// runWithDiagnostics runs a terraform command with the -json flag, and returns the parsed diagnostics with the
+// raw output.
+func runWithDiagnostics(t *testing.T, options *terraform.Options, args ...string) (Diagnostics, string, error) {
+	out, err := terraform.RunTerraformCommandE(t, options, terraform.FormatArgs(options, append(args, "-json")...)...)
+
+	return ParseDiagnostics(out), out, err
+}
+
+// planWithDiagnostics runs init and plan (with -json), the same way terraform.InitAndPlanE does.
+func planWithDiagnostics(t *testing.T, options *terraform.Options) (Diagnostics, string, error) {
+	if out, err := terraform.InitE(t, options); err != nil {
+		return ParseDiagnostics(out), out, err
+	}
+
+	return runWithDiagnostics(t, options, "plan", "-input=false", "-lock=false")
+}
+
+// applyWithDiagnostics runs init and apply (with -json), the same way terraform.InitAndApplyE does.
+func applyWithDiagnostics(t *testing.T, options *terraform.Options) (Diagnostics, string, error) {
+	if out, err := terraform.InitE(t, options); err != nil {
+		return ParseDiagnostics(out), out, err
+	}
+
+	return runWithDiagnostics(t, options, "apply", "-input=false", "-auto-approve")
+}
@@ -0,0 +1,133 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testValidationOutput = `
+Planning failed. Terraform encountered an error while generating this plan.
+
+╷
+│ Warning: Deprecated attribute
+│ 
+│ The attribute "name_prefix" is deprecated.
+╵
+╷
+│ Error: Invalid value for variable
+│ 
+│   on variables.tf line 1:
+│    1: variable "environment" {
+│     ├────────────────
+│     │ var.environment is "qa"
+│ 
+│ The environment must be one of dev, stg or prod.
+│ 
+│ This was checked by the validation rule at variables.tf:4,3-13.
+╵
+╷
+│ Error: Resource precondition failed
+│ 
+│   on main.tf line 12, in resource "random_id" "this":
+│   12:       condition     = var.byte_length >= 4
+│ 
+│ The byte length must be at least 4.
+╵
+`
+
+func TestParseHumanReadableErrors(t *testing.T) {
+	errs := parseHumanReadableErrors(testValidationOutput)
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, "Invalid value for variable", errs[0].Summary)
+	assert.Contains(t, errs[0].Detail, "The environment must be one of dev, stg or prod.")
+	assert.NotContains(t, errs[0].Detail, "deprecated")
+	assert.Equal(t, "Resource precondition failed", errs[1].Summary)
+	assert.Contains(t, errs[1].Detail, "The byte length must be at least 4.")
+
+	assert.Empty(t, parseHumanReadableErrors("No changes. Your infrastructure matches the configuration."))
+}
+
+func TestParseHumanReadableErrorsWithoutBox(t *testing.T) {
+	errs := parseHumanReadableErrors("\x1b[31mError: \x1b[0mInvalid value for variable\n\nvar.region is \"mars\"\n")
+	require.Len(t, errs, 1)
+
+	assert.Equal(t, "Invalid value for variable", errs[0].Summary)
+	assert.Equal(t, `var.region is "mars"`, errs[0].Detail)
+}
+
+const testPlanJSONStream = `{"@level":"info","@message":"Terraform 1.7.5","@module":"terraform.ui","type":"version","terraform":"1.7.5","ui":"1.2"}
+{"@level":"warn","@message":"Warning: Deprecated attribute","@module":"terraform.ui","type":"diagnostic","diagnostic":{"severity":"warning","summary":"Deprecated attribute","detail":"The attribute \"name_prefix\" is deprecated.","address":"aws_s3_bucket.this","range":{"filename":"main.tf","start":{"line":3,"column":3,"byte":40},"end":{"line":3,"column":14,"byte":51}}}}
+{"@level":"error","@message":"Error: Invalid value for variable","@module":"terraform.ui","type":"diagnostic","diagnostic":{"severity":"error","summary":"Invalid value for variable","detail":"The environment must be one of dev, stg or prod.","range":{"filename":"variables.tf","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":24,"byte":23}},"snippet":{"context":null,"code":"variable \"environment\" {","start_line":1,"highlight_start_offset":0,"highlight_end_offset":23,"values":[{"traversal":"var.environment","statement":"is \"qa\""}]}}}
+`
+
+const testValidateJSON = `{
+  "format_version": "1.0",
+  "valid": false,
+  "error_count": 1,
+  "warning_count": 0,
+  "diagnostics": [
+    {
+      "severity": "error",
+      "summary": "Unsupported argument",
+      "detail": "An argument named \"lenght\" is not expected here.",
+      "range": {
+        "filename": "main.tf",
+        "start": {"line": 9, "column": 3, "byte": 120},
+        "end": {"line": 9, "column": 9, "byte": 126}
+      }
+    }
+  ]
+}`
+
+func TestParseDiagnosticsFromJSONStream(t *testing.T) {
+	diagnostics := ParseDiagnostics(testPlanJSONStream)
+	require.Len(t, diagnostics, 2)
+
+	warnings := diagnostics.Warnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "Deprecated attribute", warnings[0].Summary)
+	assert.Equal(t, "aws_s3_bucket.this", warnings[0].Address)
+	assert.Equal(t, "main.tf", warnings[0].Range.Filename)
+
+	errs := diagnostics.Errors()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Invalid value for variable", errs[0].Summary)
+	require.NotNil(t, errs[0].Snippet)
+	assert.Equal(t, "var.environment", errs[0].Snippet.Values[0].Traversal)
+
+	assert.Contains(t, errs.String(), "error: Invalid value for variable")
+	assert.Contains(t, errs.String(), "at variables.tf:1,1")
+	assert.Contains(t, errs.String(), `var.environment is "qa"`)
+
+	assert.Empty(t, ParseDiagnostics(`{"@level":"info","type":"version","terraform":"1.7.5"}`))
+}
+
+func TestParseDiagnosticsFromValidateJSON(t *testing.T) {
+	diagnostics := ParseDiagnostics(testValidateJSON)
+	require.Len(t, diagnostics, 1)
+
+	assert.Equal(t, "Unsupported argument", diagnostics.Errors()[0].Summary)
+	assert.Equal(t, 9, diagnostics[0].Range.Start.Line)
+}
+
+func TestDiagnosticsMatching(t *testing.T) {
+	diagnostics := ParseDiagnostics(testPlanJSONStream)
+
+	deprecations, err := diagnostics.Warnings().Matching("(?i)deprecated")
+	require.NoError(t, err)
+	assert.Len(t, deprecations, 1)
+
+	deprecations, err = diagnostics.Errors().Matching("(?i)deprecated")
+	require.NoError(t, err)
+	assert.Empty(t, deprecations)
+
+	_, err = diagnostics.Matching("(")
+	assert.Error(t, err)
+
+	diagnostics.AssertWarningMatching(t, "name_prefix")
+	diagnostics.AssertNoWarningMatching(t, "(?i)unknown provider")
+	diagnostics.Warnings().AssertNoErrors(t)
+}
@@ -0,0 +1,197 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// ResourceDrift represents a resource that still has changes in a plan that was expected to be empty.
+type ResourceDrift struct {
+	// Address is the address of the drifting resource.
+	Address string
+
+	// Actions are the actions that Terraform plans for the resource.
+	Actions tfjson.Actions
+
+	// Attributes describes each drifting attribute (e.g.: `tags.owner: "a" => "b"`).
+	Attributes []string
+}
+
+// String returns a human-readable description of the drift.
+func (d ResourceDrift) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s (%v)", d.Address, d.Actions))
+	for _, attribute := range d.Attributes {
+		sb.WriteString(fmt.Sprintf("\n    ~ %s", attribute))
+	}
+
+	return sb.String()
+}
+
+// Drifts returns every resource of the plan that is not a no-op, along with the attributes that change.
+//
+// Returns:
+//   - []ResourceDrift: The drifting resources.
+func (p *Plan) Drifts() []ResourceDrift {
+	var drifts []ResourceDrift
+
+	for _, change := range p.ChangedResources() {
+		drift := ResourceDrift{
+			Address: change.Address,
+			Actions: change.Change.Actions,
+		}
+
+		if change.Change.Before != nil && change.Change.After != nil {
+			beforeSensitive, afterSensitive := change.Change.BeforeSensitive, change.Change.AfterSensitive
+			isSensitive := func(path string) bool {
+				return isAttributeMarked(beforeSensitive, path) || isAttributeMarked(afterSensitive, path)
+			}
+
+			drift.Attributes = diffAttributes(change.Change.Before, change.Change.After, change.Change.AfterUnknown, isSensitive, "")
+		}
+
+		drifts = append(drifts, drift)
+	}
+
+	return drifts
+}
+
+// AssertNoDrift checks that the plan has no changes, reporting the drifting addresses and attributes otherwise.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertNoDrift(t *testing.T) {
+	drifts := p.Drifts()
+	if len(drifts) == 0 {
+		return
+	}
+
+	report := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		report = append(report, "  - "+drift.String())
+	}
+
+	require.Failf(t, "The plan is not empty",
+		"%d resource(s) keep drifting:\n%s", len(drifts), strings.Join(report, "\n"))
+}
+
+// IdempotencyStage applies the Terraform stage, plans again against the same working directory and checks that
+// the second plan is empty. If it is not, it reports exactly which addresses and attributes keep drifting.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+func (c *StageClient) IdempotencyStage(t *testing.T, options *terraform.Options) {
+	c.ApplyStage(t, options)
+	c.PlanAndShowStage(t, options).AssertNoDrift(t)
+}
+
+// diffAttributes compares the before and after values of a change, and describes every attribute that differs.
+//
+// Parameters:
+//   - before: The value before the change.
+//   - after: The value after the change.
+//   - unknown: The matching after_unknown structure.
+//   - isSensitive: Reports whether the attribute at a path is sensitive, so its values are not printed.
+//   - path: The attribute path of the values being compared.
+//
+// Returns:
+//   - []string: The sorted descriptions of the attributes that differ.
+func diffAttributes(before, after, unknown interface{}, isSensitive func(path string) bool, path string) []string {
+	if path != "" && isSensitive(path) {
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+
+		return []string{fmt.Sprintf("%s: %s => %s", path, snapshotSensitiveValue, snapshotSensitiveValue)}
+	}
+
+	if b, ok := unknown.(bool); ok && b {
+		return []string{fmt.Sprintf("%s: %s => %s", pathOrRoot(path), formatDriftValue(before), snapshotUnknownValue)}
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+
+	if beforeIsMap && afterIsMap {
+		unknownMap, _ := unknown.(map[string]interface{})
+
+		keys := make(map[string]bool)
+		for k := range beforeMap {
+			keys[k] = true
+		}
+		for k := range afterMap {
+			keys[k] = true
+		}
+		for k := range unknownMap {
+			keys[k] = true
+		}
+
+		var diffs []string
+		for k := range keys {
+			diffs = append(diffs, diffAttributes(beforeMap[k], afterMap[k], unknownMap[k], isSensitive, joinAttributePath(path, k))...)
+		}
+
+		sort.Strings(diffs)
+
+		return diffs
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		unknownList, _ := unknown.([]interface{})
+
+		var diffs []string
+		for i := range beforeList {
+			diffs = append(diffs, diffAttributes(beforeList[i], afterList[i], elementAt(unknownList, i), isSensitive, joinAttributePath(path, strconv.Itoa(i)))...)
+		}
+
+		return diffs
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s: %s => %s", pathOrRoot(path), formatDriftValue(before), formatDriftValue(after))}
+}
+
+// joinAttributePath appends a segment to an attribute path.
+func joinAttributePath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+
+	return path + "." + segment
+}
+
+// pathOrRoot returns the path, or a placeholder for the root of the value.
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
+
+// formatDriftValue formats a value of a change in its JSON form.
+func formatDriftValue(value interface{}) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(out)
+}
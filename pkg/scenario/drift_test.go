@@ -0,0 +1,52 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAttributes(t *testing.T) {
+	before := map[string]interface{}{
+		"bucket": "my-bucket",
+		"tags":   map[string]interface{}{"owner": "a", "team": "x"},
+		"rules":  []interface{}{map[string]interface{}{"days": float64(30)}},
+		"acl":    "private",
+	}
+	after := map[string]interface{}{
+		"bucket": "my-bucket",
+		"tags":   map[string]interface{}{"owner": "b", "team": "x"},
+		"rules":  []interface{}{map[string]interface{}{"days": float64(60)}},
+	}
+	unknown := map[string]interface{}{"arn": true}
+	notSensitive := func(string) bool { return false }
+
+	assert.Equal(t, []string{
+		`acl: "private" => null`,
+		`arn: null => (known after apply)`,
+		`rules.0.days: 30 => 60`,
+		`tags.owner: "a" => "b"`,
+	}, diffAttributes(before, after, unknown, notSensitive, ""))
+
+	assert.Empty(t, diffAttributes(before, before, nil, notSensitive, ""))
+}
+
+func TestPlanDrifts(t *testing.T) {
+	plan, err := NewPlanFromJSON(testPlanJSON)
+	require.NoError(t, err)
+
+	drifts := plan.Drifts()
+	require.Len(t, drifts, 2)
+
+	assert.Equal(t, "random_uuid.this", drifts[0].Address)
+	assert.Empty(t, drifts[0].Attributes)
+
+	assert.Equal(t, "aws_s3_bucket.this", drifts[1].Address)
+	assert.Equal(t, []string{
+		`arn: null => (known after apply)`,
+		`secret: (sensitive value) => (sensitive value)`,
+		`tags.owner: "a" => "b"`,
+	}, drifts[1].Attributes)
+	assert.Contains(t, drifts[1].String(), "aws_s3_bucket.this ([update])")
+}
@@ -0,0 +1,129 @@
+package scenario
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// ExpectedError describes the diagnostic that a failing stage is expected to produce. Empty fields are not checked.
+type ExpectedError struct {
+	// Summary is a regular expression matched against the summary of the error (e.g.: "Invalid value for variable").
+	Summary string
+
+	// Detail is a regular expression matched against the detail of the error, typically the error_message of a
+	// validation block, a precondition or a postcondition.
+	Detail string
+
+	// Variable is the name of the variable the error must point at (e.g.: "environment").
+	Variable string
+}
+
+// String returns a human-readable description of the expected error.
+func (e ExpectedError) String() string {
+	return fmt.Sprintf("summary=%q detail=%q variable=%q", e.Summary, e.Detail, e.Variable)
+}
+
+// PlanExpectError plans the Terraform stage and checks that it fails with an error that matches the expected
+// one. It is used to test validation blocks and preconditions.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//   - expected: The expected error.
+//
+// Example:
+//
+//	s.Stg.PlanExpectError(t, options, scenario.ExpectedError{
+//	    Summary:  "Invalid value for variable",
+//	    Detail:   "must be one of",
+//	    Variable: "environment",
+//	})
+func (c *StageClient) PlanExpectError(t *testing.T, options *terraform.Options, expected ExpectedError) {
+	diagnostics, out, err := planWithDiagnostics(t, options)
+	require.Errorf(t, err, "The plan was expected to fail with %s, but it succeeded: %s", expected, out)
+
+	assertExpectedError(t, diagnostics, expected)
+}
+
+// ApplyExpectError applies the Terraform stage and checks that it fails with an error that matches the expected
+// one. It is used to test postconditions. Resources created before the failure are left in place, so the stage
+// should still be destroyed afterwards.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//   - expected: The expected error.
+func (c *StageClient) ApplyExpectError(t *testing.T, options *terraform.Options, expected ExpectedError) {
+	c.requireAllowedAWSTarget(t, options)
+
+	diagnostics, out, err := applyWithDiagnostics(t, options)
+	require.Errorf(t, err, "The apply was expected to fail with %s, but it succeeded: %s", expected, out)
+
+	assertExpectedError(t, diagnostics, expected)
+}
+
+// assertExpectedError checks that at least one of the error diagnostics matches the expected error.
+func assertExpectedError(t *testing.T, diagnostics Diagnostics, expected ExpectedError) {
+	errs := diagnostics.Errors()
+	require.NotEmptyf(t, errs, "Terraform failed, but no error was found in its output")
+
+	found, err := matchesExpectedError(errs, expected)
+	require.NoErrorf(t, err, "Invalid expected error %s", expected)
+
+	if !found {
+		require.Failf(t, "No error matches the expected one", "Expected an error with %s, found:\n%s", expected, errs)
+	}
+}
+
+// matchesExpectedError reports whether at least one of the diagnostics matches the expected error.
+func matchesExpectedError(diagnostics Diagnostics, expected ExpectedError) (bool, error) {
+	summaryPattern, err := regexp.Compile(expected.Summary)
+	if err != nil {
+		return false, fmt.Errorf("invalid summary pattern: %v", err)
+	}
+
+	detailPattern, err := regexp.Compile(expected.Detail)
+	if err != nil {
+		return false, fmt.Errorf("invalid detail pattern: %v", err)
+	}
+
+	for _, diagnostic := range diagnostics {
+		if summaryPattern.MatchString(diagnostic.Summary) && detailPattern.MatchString(diagnostic.Detail) &&
+			pointsAtVariable(diagnostic, expected.Variable) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// pointsAtVariable reports whether a diagnostic references the given variable, either through its declaration or
+// through a traversal in the source snippet. Diagnostics parsed from the human-readable output carry the snippet
+// in their detail.
+func pointsAtVariable(diagnostic Diagnostic, variable string) bool {
+	if variable == "" {
+		return true
+	}
+
+	declaration := fmt.Sprintf(`variable %q`, variable)
+	traversal := regexp.MustCompile(`\bvar\.` + regexp.QuoteMeta(variable) + `\b`)
+
+	if snippet := diagnostic.Snippet; snippet != nil {
+		if strings.Contains(snippet.Code, declaration) || traversal.MatchString(snippet.Code) {
+			return true
+		}
+
+		for _, value := range snippet.Values {
+			if traversal.MatchString(value.Traversal) {
+				return true
+			}
+		}
+	}
+
+	return strings.Contains(diagnostic.Detail, declaration) || traversal.MatchString(diagnostic.Detail)
+}
@@ -0,0 +1,48 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesExpectedError(t *testing.T) {
+	errs := parseHumanReadableErrors(testValidationOutput)
+
+	testCases := []struct {
+		name     string
+		expected ExpectedError
+		matches  bool
+	}{
+		{"validation rule", ExpectedError{Summary: "^Invalid value for variable$", Detail: "must be one of", Variable: "environment"}, true},
+		{"precondition", ExpectedError{Summary: "precondition", Variable: "byte_length"}, true},
+		{"any error", ExpectedError{}, true},
+		{"wrong variable", ExpectedError{Summary: "Invalid value for variable", Variable: "byte_length"}, false},
+		{"variable prefix", ExpectedError{Variable: "env"}, false},
+		{"wrong detail", ExpectedError{Detail: "must be a valid region"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, err := matchesExpectedError(errs, tc.expected)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, matches)
+		})
+	}
+
+	_, err := matchesExpectedError(errs, ExpectedError{Summary: "("})
+	assert.Error(t, err)
+}
+
+func TestMatchesExpectedErrorWithSnippet(t *testing.T) {
+	diagnostics := ParseDiagnostics(testPlanJSONStream)
+
+	matches, err := matchesExpectedError(diagnostics.Errors(), ExpectedError{Summary: "Invalid value for variable", Variable: "environment"})
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = matchesExpectedError(diagnostics.Errors(), ExpectedError{Variable: "region"})
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
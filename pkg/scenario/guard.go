@@ -0,0 +1,267 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+var awsAccountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// awsAccessKeyEnvVar is the environment variable that sets the AWS access key of Terraform.
+const awsAccessKeyEnvVar = "AWS_ACCESS_KEY_ID"
+
+// awsCredentialEnvVars are the environment variables that change the AWS credentials, or the profile, Terraform
+// resolves its identity from.
+var awsCredentialEnvVars = []string{
+	awsAccessKeyEnvVar,
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_PROFILE",
+	"AWS_DEFAULT_PROFILE",
+	"AWS_ROLE_ARN",
+	"AWS_WEB_IDENTITY_TOKEN_FILE",
+	"AWS_CONFIG_FILE",
+	"AWS_SHARED_CREDENTIALS_FILE",
+}
+
+// awsRegionEnvVars are the environment variables that set the AWS region of Terraform, by precedence.
+var awsRegionEnvVars = []string{"AWS_REGION", "AWS_DEFAULT_REGION"}
+
+// awsGuard refuses to change the infrastructure unless the AWS account and region Terraform runs against are
+// allowed. The identity is resolved once per scenario, before the first stage that changes the infrastructure.
+type awsGuard struct {
+	aws             cloudprovider.AWSAdapter
+	allowedAccounts []string
+	allowedRegions  []string
+
+	// credentials are the AWS credentials exported to Terraform by the scenario, nil if Terraform resolves them
+	// itself from the environment.
+	credentials map[string]string
+
+	once     sync.Once
+	identity *cloudprovider.CallerIdentity
+	err      error
+}
+
+// WithAllowedAWSAccounts only lets the scenario apply (or destroy) when the AWS account of the caller is one of
+// the given accounts. The accounts listed in the TFTEST_ALLOWED_ACCOUNTS environment variable are allowed too.
+// It requires WithAWS, whose credentials are the ones Terraform runs with.
+//
+// Parameters:
+//   - accounts: The IDs of the allowed AWS accounts.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithAllowedAWSAccounts(accounts ...string) OptFn {
+	return func(o *Options) error {
+		for _, account := range accounts {
+			if !awsAccountIDPattern.MatchString(account) {
+				return fmt.Errorf("the AWS account ID %q is not valid, it must have 12 digits", account)
+			}
+		}
+
+		o.allowedAccounts = append(o.allowedAccounts, accounts...)
+
+		return nil
+	}
+}
+
+// WithAllowedAWSRegions only lets the scenario apply (or destroy) when the AWS region of the scenario (see
+// WithAWS), and the one set for Terraform through AWS_REGION or AWS_DEFAULT_REGION, is one of the given regions.
+// A region hardcoded in the provider block of the module is not checked. It requires WithAWS.
+//
+// Parameters:
+//   - regions: The allowed AWS regions.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithAllowedAWSRegions(regions ...string) OptFn {
+	return func(o *Options) error {
+		o.allowedRegions = append(o.allowedRegions, regions...)
+		return nil
+	}
+}
+
+// newAWSGuard builds the guard of the scenario from its options and the TFTEST_ALLOWED_ACCOUNTS environment
+// variable. It returns nil if no allowlist is configured. Without an AWS client, the accounts of the environment
+// are checked against the identity of the default AWS credential chain, which Terraform resolves too, as long as
+// the Terraform options do not set AWS credentials or a profile of their own.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - o: The options of the scenario.
+//   - aws: The AWS Cloud Provider (Client) of the scenario, nil if it is not enabled.
+//   - credentials: The AWS credentials exported to Terraform, nil if none are.
+//
+// Returns:
+//   - *awsGuard: The guard, or nil if there is nothing to check.
+//   - error: An error if the allowlist is configured but cannot be checked.
+func newAWSGuard(t *testing.T, o *Options, aws cloudprovider.AWSAdapter, credentials map[string]string) (*awsGuard, error) {
+	envAccounts := allowedAccountsFromEnv()
+
+	if len(o.allowedAccounts) == 0 && len(o.allowedRegions) == 0 && len(envAccounts) == 0 {
+		return nil, nil
+	}
+
+	if aws == nil {
+		if len(o.allowedAccounts) > 0 || len(o.allowedRegions) > 0 {
+			return nil, fmt.Errorf("the allowed AWS accounts and regions can only be checked with the AWS Cloud Provider (Client), see WithAWS")
+		}
+
+		t.Logf("%s is set, the AWS account is resolved from the default AWS credential chain", AllowedAccountsEnvVar)
+
+		defaultAWS, err := cloudprovider.NewAWS("")
+		if err != nil {
+			return nil, fmt.Errorf("%s is set, but the AWS account cannot be resolved: %v", AllowedAccountsEnvVar, err)
+		}
+
+		aws = defaultAWS
+	}
+
+	return &awsGuard{
+		aws:             aws,
+		allowedAccounts: append(append([]string(nil), o.allowedAccounts...), envAccounts...),
+		allowedRegions:  o.allowedRegions,
+		credentials:     credentials,
+	}, nil
+}
+
+// check resolves the AWS account of the scenario, once, and checks it against the allowlist with the region and
+// the credentials Terraform runs with.
+func (g *awsGuard) check(t *testing.T, options *terraform.Options) error {
+	g.once.Do(func() {
+		g.identity, g.err = g.aws.GetCallerIdentity(context.TODO())
+		if g.err == nil {
+			t.Logf("The scenario runs against AWS %s, in region %s", g.identity, g.aws.GetRegion())
+		}
+	})
+
+	if g.err != nil {
+		return g.err
+	}
+
+	var envVars map[string]string
+	if options != nil {
+		envVars = options.EnvVars
+	}
+
+	if g.credentials != nil && envVars[awsAccessKeyEnvVar] != g.credentials[awsAccessKeyEnvVar] {
+		return fmt.Errorf("the AWS credentials of the Terraform options are not the ones of the AWS Cloud Provider (Client), " +
+			"whose account was checked")
+	}
+
+	if unresolved := unresolvedAWSCredentialEnvVars(envVars, g.credentials); len(unresolved) > 0 {
+		return fmt.Errorf("the Terraform options set %v, so Terraform may not run with the AWS identity that was checked", unresolved)
+	}
+
+	for _, region := range terraformAWSRegions(envVars, g.aws.GetRegion()) {
+		if err := checkAllowedAWSTarget(g.identity.Account, region, g.allowedAccounts, g.allowedRegions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requireAllowedAWSTarget fails the test, before anything is changed, if the scenario is not allowed to change the
+// infrastructure of its AWS account and region.
+func (c *StageClient) requireAllowedAWSTarget(t *testing.T, options *terraform.Options) {
+	if c.guard == nil {
+		return
+	}
+
+	require.NoErrorf(t, c.guard.check(t, options), "Refusing to change the infrastructure")
+}
+
+// terraformAWSRegions returns the AWS regions Terraform may run in: the ones set in its environment variables,
+// or the region of the AWS client if none is.
+//
+// Parameters:
+//   - envVars: The environment variables of Terraform.
+//   - fallback: The region of the AWS client.
+//
+// Returns:
+//   - []string: The regions to check.
+func terraformAWSRegions(envVars map[string]string, fallback string) []string {
+	var regions []string
+
+	for _, key := range awsRegionEnvVars {
+		if region := envVars[key]; region != "" && !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+
+	if len(regions) == 0 {
+		regions = append(regions, fallback)
+	}
+
+	return regions
+}
+
+// unresolvedAWSCredentialEnvVars returns the AWS credential and profile environment variables of Terraform whose
+// value is not the one exported by the scenario. The identity Terraform resolves from them was not checked.
+//
+// Parameters:
+//   - envVars: The environment variables of Terraform.
+//   - credentials: The AWS credentials exported to Terraform, nil if none are.
+//
+// Returns:
+//   - []string: The names of the variables that were not resolved by the guard.
+func unresolvedAWSCredentialEnvVars(envVars, credentials map[string]string) []string {
+	var unresolved []string
+
+	for _, key := range awsCredentialEnvVars {
+		if value := envVars[key]; value != "" && value != credentials[key] {
+			unresolved = append(unresolved, key)
+		}
+	}
+
+	return unresolved
+}
+
+// checkAllowedAWSTarget checks the account and the region against the allowlists. An empty allowlist allows
+// everything.
+//
+// Parameters:
+//   - account: The ID of the AWS account.
+//   - region: The AWS region.
+//   - allowedAccounts: The allowed AWS accounts.
+//   - allowedRegions: The allowed AWS regions.
+//
+// Returns:
+//   - error: An error if the account or the region is not allowed.
+func checkAllowedAWSTarget(account, region string, allowedAccounts, allowedRegions []string) error {
+	if len(allowedAccounts) > 0 && !slices.Contains(allowedAccounts, account) {
+		return fmt.Errorf("the AWS account %s is not in the allowed accounts %v", account, allowedAccounts)
+	}
+
+	if len(allowedRegions) > 0 && !slices.Contains(allowedRegions, region) {
+		return fmt.Errorf("the AWS region %s is not in the allowed regions %v", region, allowedRegions)
+	}
+
+	return nil
+}
+
+// allowedAccountsFromEnv returns the accounts listed, comma-separated, in the TFTEST_ALLOWED_ACCOUNTS environment
+// variable.
+func allowedAccountsFromEnv() []string {
+	var accounts []string
+
+	for _, account := range strings.Split(os.Getenv(AllowedAccountsEnvVar), ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts
+}
@@ -0,0 +1,129 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/cloudprovider"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAWS is an AWS Cloud Provider (Client) that only knows its identity and region.
+type fakeAWS struct {
+	cloudprovider.AWSAdapter
+
+	account string
+	region  string
+	calls   int
+}
+
+func (f *fakeAWS) GetCallerIdentity(_ context.Context) (*cloudprovider.CallerIdentity, error) {
+	f.calls++
+	return &cloudprovider.CallerIdentity{Account: f.account, ARN: "arn:aws:iam::" + f.account + ":user/tests"}, nil
+}
+
+func (f *fakeAWS) GetRegion() string {
+	return f.region
+}
+
+func TestCheckAllowedAWSTarget(t *testing.T) {
+	assert.NoError(t, checkAllowedAWSTarget("111111111111", "us-east-1", nil, nil))
+	assert.NoError(t, checkAllowedAWSTarget("111111111111", "us-east-1", []string{"111111111111"}, []string{"us-east-1", "eu-west-1"}))
+	assert.ErrorContains(t, checkAllowedAWSTarget("999999999999", "us-east-1", []string{"111111111111"}, nil), "account 999999999999")
+	assert.ErrorContains(t, checkAllowedAWSTarget("111111111111", "ap-south-1", nil, []string{"us-east-1"}), "region ap-south-1")
+}
+
+func TestAllowedAccountsFromEnv(t *testing.T) {
+	t.Setenv(AllowedAccountsEnvVar, " 111111111111, ,222222222222 ")
+	assert.Equal(t, []string{"111111111111", "222222222222"}, allowedAccountsFromEnv())
+
+	t.Setenv(AllowedAccountsEnvVar, "")
+	assert.Empty(t, allowedAccountsFromEnv())
+}
+
+func TestNewAWSGuard(t *testing.T) {
+	t.Setenv(AllowedAccountsEnvVar, "")
+
+	guard, err := newAWSGuard(t, &Options{}, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, guard)
+
+	_, err = newAWSGuard(t, &Options{allowedAccounts: []string{"111111111111"}}, nil, nil)
+	assert.Error(t, err, "The allowlist cannot be checked without the AWS client")
+
+	t.Setenv(AllowedAccountsEnvVar, "222222222222")
+
+	guard, err = newAWSGuard(t, &Options{}, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, guard, "The environment allowlist is checked with the default AWS credential chain")
+	assert.NotNil(t, guard.aws)
+
+	production := &fakeAWS{account: "999999999999", region: "us-east-1"}
+	guard, err = newAWSGuard(t, &Options{allowedAccounts: []string{"111111111111"}}, production, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "222222222222"}, guard.allowedAccounts)
+
+	assert.ErrorContains(t, guard.check(t, nil), "not in the allowed accounts")
+	assert.Error(t, guard.check(t, nil))
+	assert.Equal(t, 1, production.calls, "The identity is resolved once per scenario")
+
+	sandbox := &fakeAWS{account: "222222222222", region: "eu-west-1"}
+	credentials := map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_REGION": "eu-west-1"}
+	guard, err = newAWSGuard(t, &Options{allowedRegions: []string{"eu-west-1"}}, sandbox, credentials)
+	require.NoError(t, err)
+	assert.NoError(t, guard.check(t, &terraform.Options{EnvVars: map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_REGION": "eu-west-1"}}))
+	assert.ErrorContains(t, guard.check(t, &terraform.Options{EnvVars: map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_REGION": "us-east-1"}}),
+		"region us-east-1", "The region of Terraform is checked")
+	assert.ErrorContains(t, guard.check(t, &terraform.Options{EnvVars: map[string]string{"AWS_ACCESS_KEY_ID": "AKIAOTHER", "AWS_REGION": "eu-west-1"}}),
+		"credentials", "Terraform must run with the credentials that were checked")
+	assert.ErrorContains(t, guard.check(t, &terraform.Options{EnvVars: map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_PROFILE": "production"}}),
+		"AWS_PROFILE", "A profile that was not checked cannot be passed to Terraform")
+}
+
+func TestAWSGuardWithoutAWSClient(t *testing.T) {
+	t.Setenv(AllowedAccountsEnvVar, "222222222222")
+
+	guard, err := newAWSGuard(t, &Options{}, nil, nil)
+	require.NoError(t, err)
+
+	// The identity of the default AWS credential chain is checked, but Terraform would run with other credentials.
+	sandbox := &fakeAWS{account: "222222222222", region: "eu-west-1"}
+	guard.aws = sandbox
+
+	assert.NoError(t, guard.check(t, &terraform.Options{EnvVars: map[string]string{"TF_LOG": "DEBUG"}}))
+
+	for _, envVars := range []map[string]string{
+		{"AWS_PROFILE": "production"},
+		{"AWS_ACCESS_KEY_ID": "AKIAPRODUCTION", "AWS_SECRET_ACCESS_KEY": "secret"},
+		{"AWS_ROLE_ARN": "arn:aws:iam::999999999999:role/admin", "AWS_WEB_IDENTITY_TOKEN_FILE": "/tmp/token"},
+	} {
+		assert.ErrorContains(t, guard.check(t, &terraform.Options{EnvVars: envVars}), "may not run with the AWS identity that was checked",
+			"Terraform must not run with credentials the guard did not resolve: %v", envVars)
+	}
+}
+
+func TestUnresolvedAWSCredentialEnvVars(t *testing.T) {
+	credentials := map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_REGION": "eu-west-1"}
+
+	assert.Empty(t, unresolvedAWSCredentialEnvVars(nil, nil))
+	assert.Empty(t, unresolvedAWSCredentialEnvVars(map[string]string{"AWS_REGION": "us-east-1"}, nil))
+	assert.Empty(t, unresolvedAWSCredentialEnvVars(map[string]string{"AWS_ACCESS_KEY_ID": "AKIASANDBOX", "AWS_SECRET_ACCESS_KEY": "secret"}, credentials))
+	assert.Equal(t, []string{"AWS_SECRET_ACCESS_KEY", "AWS_PROFILE"},
+		unresolvedAWSCredentialEnvVars(map[string]string{"AWS_SECRET_ACCESS_KEY": "other", "AWS_PROFILE": "production"}, credentials))
+}
+
+func TestTerraformAWSRegions(t *testing.T) {
+	assert.Equal(t, []string{"us-east-1"}, terraformAWSRegions(nil, "us-east-1"))
+	assert.Equal(t, []string{"eu-west-1"}, terraformAWSRegions(map[string]string{"AWS_REGION": "eu-west-1", "AWS_DEFAULT_REGION": "eu-west-1"}, "us-east-1"))
+	assert.Equal(t, []string{"eu-west-1", "ap-south-1"}, terraformAWSRegions(map[string]string{"AWS_REGION": "eu-west-1", "AWS_DEFAULT_REGION": "ap-south-1"}, "us-east-1"))
+}
+
+func TestWithAllowedAWSAccounts(t *testing.T) {
+	o := &Options{}
+
+	require.NoError(t, WithAllowedAWSAccounts("111111111111")(o))
+	assert.Equal(t, []string{"111111111111"}, o.allowedAccounts)
+	assert.Error(t, WithAllowedAWSAccounts("1111")(o))
+}
@@ -0,0 +1,222 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// OutputValue represents a single output, as returned by 'terraform output -json'.
+type OutputValue struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Outputs represents all the outputs of a Terraform module after apply. The outputs are read once, and all
+// the output assertions run against the same values.
+type Outputs struct {
+	// Values holds every output, keyed by the output name.
+	Values map[string]OutputValue
+
+	// JSON is the raw JSON returned by 'terraform output -json'.
+	JSON string
+}
+
+// NewOutputsFromJSON creates new Outputs from the JSON returned by 'terraform output -json'.
+//
+// Parameters:
+//   - jsonOutputs: The JSON output of 'terraform output -json'.
+//
+// Returns:
+//   - *Outputs: The parsed outputs.
+//   - error: An error if the outputs could not be parsed.
+func NewOutputsFromJSON(jsonOutputs string) (*Outputs, error) {
+	values := make(map[string]OutputValue)
+	if err := json.Unmarshal([]byte(jsonOutputs), &values); err != nil {
+		return nil, fmt.Errorf("failed to parse the terraform outputs: %v", err)
+	}
+
+	return &Outputs{
+		Values: values,
+		JSON:   jsonOutputs,
+	}, nil
+}
+
+// OutputStage reads all the outputs of the Terraform module once, using 'terraform output -json'.
+// It is meant to be run after the ApplyStage.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - *Outputs: The outputs, parsed and ready to be asserted.
+func (c *StageClient) OutputStage(t *testing.T, options *terraform.Options) *Outputs {
+	out, err := terraform.RunTerraformCommandAndGetStdoutE(t, options, "output", "-no-color", "-json")
+	require.NoErrorf(t, err, "Failed to read terraform outputs: %s", out)
+
+	outputs, err := NewOutputsFromJSON(out)
+	require.NoErrorf(t, err, "Failed to parse terraform outputs")
+
+	return outputs
+}
+
+// Names returns the sorted names of all the outputs.
+//
+// Returns:
+//   - []string: The names of the outputs.
+func (o *Outputs) Names() []string {
+	names := make([]string, 0, len(o.Values))
+	for name := range o.Values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Output returns the output with the given name, failing the test if it does not exist.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+//
+// Returns:
+//   - OutputValue: The output.
+func (o *Outputs) Output(t *testing.T, name string) OutputValue {
+	output, found := o.Values[name]
+	require.Truef(t, found, "Output %s was not found, the available outputs are: %v", name, o.Names())
+
+	return output
+}
+
+// Get returns the decoded value of the output with the given name (maps, slices, float64, string, bool or nil).
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+//
+// Returns:
+//   - interface{}: The decoded value of the output.
+func (o *Outputs) Get(t *testing.T, name string) interface{} {
+	return OutputAs[interface{}](t, o, name)
+}
+
+// OutputAs decodes the value of the output with the given name into the type T.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - o: The outputs.
+//   - name: The name of the output.
+//
+// Returns:
+//   - T: The decoded value of the output.
+//
+// Example:
+//
+//	type bucket struct {
+//	    ARN  string `json:"arn"`
+//	    Name string `json:"name"`
+//	}
+//	b := scenario.OutputAs[bucket](t, outputs, "bucket")
+func OutputAs[T any](t *testing.T, o *Outputs, name string) T {
+	output := o.Output(t, name)
+
+	var value T
+	require.NoErrorf(t, json.Unmarshal(output.Value, &value), "Failed to decode output %s into %T", name, value)
+
+	return value
+}
+
+// AssertExists checks that the output with the given name exists.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+func (o *Outputs) AssertExists(t *testing.T, name string) {
+	o.Output(t, name)
+}
+
+// AssertEqual checks that the output has the expected value. Nested objects and lists are deeply compared,
+// and numbers are compared regardless of their Go type.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+//   - expected: The expected value.
+func (o *Outputs) AssertEqual(t *testing.T, name string, expected interface{}) {
+	actual := o.Get(t, name)
+
+	normalizedExpected, err := normalizeJSONValue(expected)
+	require.NoErrorf(t, err, "Failed to normalize the expected value of output %s", name)
+
+	require.Equalf(t, normalizedExpected, actual, "Output %s does not have the expected value", name)
+}
+
+// AssertMatches checks that the output matches the given regular expression. String outputs are matched
+// as they are, and any other output is matched against its JSON representation.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+//   - pattern: The regular expression to match.
+func (o *Outputs) AssertMatches(t *testing.T, name, pattern string) {
+	output := o.Output(t, name)
+
+	re, err := regexp.Compile(pattern)
+	require.NoErrorf(t, err, "Invalid regular expression %s for output %s", pattern, name)
+
+	var value string
+	if json.Unmarshal(output.Value, &value) != nil {
+		var compacted bytes.Buffer
+		require.NoErrorf(t, json.Compact(&compacted, output.Value), "Failed to compact the value of output %s", name)
+
+		value = compacted.String()
+	}
+
+	require.Truef(t, re.MatchString(value), "Output %s does not match the regular expression %s", name, pattern)
+}
+
+// AssertSensitive checks that the output is marked as sensitive.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+func (o *Outputs) AssertSensitive(t *testing.T, name string) {
+	require.Truef(t, o.Output(t, name).Sensitive, "Output %s was expected to be sensitive", name)
+}
+
+// AssertNotSensitive checks that the output is not marked as sensitive.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - name: The name of the output.
+func (o *Outputs) AssertNotSensitive(t *testing.T, name string) {
+	require.Falsef(t, o.Output(t, name).Sensitive, "Output %s was expected not to be sensitive", name)
+}
+
+// AssertJSONWithJSONPath performs JSON path assertions against the output values. The queried document
+// is an object that maps each output name to its value, e.g.: '{.random_id.hex}'.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - testCases: An array of JSON path test cases.
+func (o *Outputs) AssertJSONWithJSONPath(t *testing.T, testCases []JSONPathTestCases) {
+	values := make(map[string]json.RawMessage, len(o.Values))
+	for name, output := range o.Values {
+		values[name] = output.Value
+	}
+
+	doc, err := json.Marshal(values)
+	require.NoErrorf(t, err, "Failed to marshal the output values")
+
+	assertJSONWithJSONPath(t, doc, testCases)
+}
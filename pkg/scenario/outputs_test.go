@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOutputsJSON = `{
+  "is_enabled": {"sensitive": false, "type": "bool", "value": true},
+  "random_id": {"sensitive": false, "type": ["object", {"hex": "string", "byte_length": "number"}], "value": {"hex": "a1b2c3d4", "byte_length": 4}},
+  "random_password": {"sensitive": true, "type": "string", "value": "s3cr3t"},
+  "tags_set": {"sensitive": false, "type": ["map", "string"], "value": {"owner": "team"}}
+}`
+
+func TestOutputs(t *testing.T) {
+	outputs, err := NewOutputsFromJSON(testOutputsJSON)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"is_enabled", "random_id", "random_password", "tags_set"}, outputs.Names())
+
+	type randomID struct {
+		Hex        string `json:"hex"`
+		ByteLength int    `json:"byte_length"`
+	}
+
+	assert.Equal(t, randomID{Hex: "a1b2c3d4", ByteLength: 4}, OutputAs[randomID](t, outputs, "random_id"))
+	assert.Equal(t, map[string]string{"owner": "team"}, OutputAs[map[string]string](t, outputs, "tags_set"))
+	assert.Equal(t, true, outputs.Get(t, "is_enabled"))
+
+	outputs.AssertExists(t, "random_id")
+	outputs.AssertEqual(t, "random_id", map[string]interface{}{"hex": "a1b2c3d4", "byte_length": 4})
+	outputs.AssertMatches(t, "random_password", `^s3`)
+	outputs.AssertMatches(t, "random_id", `"hex":"a1b2`)
+	outputs.AssertSensitive(t, "random_password")
+	outputs.AssertNotSensitive(t, "tags_set")
+	outputs.AssertJSONWithJSONPath(t, []JSONPathTestCases{
+		{
+			TestName:          "random_id hex",
+			ExpectedValue:     "a1b2c3d4",
+			JSONPathToCompare: "{.random_id.hex}",
+			TestType:          ShouldBeEqual,
+		},
+	})
+}
+
+func TestNewOutputsFromJSONInvalid(t *testing.T) {
+	_, err := NewOutputsFromJSON("not json")
+	assert.Error(t, err)
+}
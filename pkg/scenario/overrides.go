@@ -0,0 +1,352 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// overrideFile is a Terraform override file written into the working directory of the scenario.
+type overrideFile struct {
+	name    string
+	content []byte
+}
+
+// backendOverride is the backend forced on the scenario through an override file.
+type backendOverride struct {
+	backendType string
+	config      map[string]interface{}
+}
+
+// WithLocalBackend forces the local backend on the scenario, whatever backend the module declares. The state is
+// kept in the copy of the module the scenario works on, and its path is available through Client.GetStateFilePath.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithLocalBackend() OptFn {
+	return WithBackendOverride(LocalBackend, nil)
+}
+
+// WithBackendOverride forces the given backend on the scenario, whatever backend the module declares. The backend
+// block is written into a generated override file, which is removed once the test finishes. The override file is
+// only written into a copy of the module (see WithParallel), which is made even without WithParallel, so
+// scenarios sharing the module never see each other's backend.
+//
+// Parameters:
+//   - backendType: The type of the backend (e.g.: "local", "s3").
+//   - config: The arguments of the backend block. Only strings, booleans and numbers are supported.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+//
+// Example:
+//
+//	scenario.WithBackendOverride("s3", map[string]interface{}{
+//	    "bucket":  "my-test-states",
+//	    "key":     "tftest/terraform.tfstate",
+//	    "region":  "us-east-1",
+//	    "encrypt": true,
+//	})
+func WithBackendOverride(backendType string, config map[string]interface{}) OptFn {
+	return func(o *Options) error {
+		if backendType == "" {
+			return fmt.Errorf("the backend type cannot be empty")
+		}
+
+		if _, err := renderBackendOverride(backendType, config); err != nil {
+			return err
+		}
+
+		o.backend = &backendOverride{
+			backendType: backendType,
+			config:      config,
+		}
+
+		return nil
+	}
+}
+
+// WithOverrideFile injects an arbitrary Terraform override file (override.tf or *_override.tf) into the working
+// directory of the scenario, to replace provider blocks, data sources or any other block of the module without
+// touching its sources: the file is only written into a copy of the module (see WithParallel), which is made even
+// without WithParallel. The file is removed once the test finishes.
+//
+// Parameters:
+//   - name: The name of the file (e.g.: "provider_override.tf").
+//   - content: The HCL content of the file.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+//
+// Example:
+//
+//	scenario.WithOverrideFile("data_override.tf", `
+//	data "aws_caller_identity" "current" {}
+//	`)
+func WithOverrideFile(name, content string) OptFn {
+	return func(o *Options) error {
+		if err := validateOverrideFile(name, []byte(content)); err != nil {
+			return err
+		}
+
+		o.overrideFiles = append(o.overrideFiles, overrideFile{name: name, content: []byte(content)})
+
+		return nil
+	}
+}
+
+// WithAWSProviderOverride points the AWS provider of the module at a local stand-in (e.g.: LocalStack), with
+// fake credentials. See AWSProviderOverride.
+//
+// Parameters:
+//   - endpoint: The endpoint of the stand-in (e.g.: "http://localhost:4566").
+//   - services: The services whose endpoint is overridden (e.g.: "s3", "sqs").
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithAWSProviderOverride(endpoint string, services ...string) OptFn {
+	return WithOverrideFile(AWSProviderOverrideFile, AWSProviderOverride(endpoint, services...))
+}
+
+// InjectOverrideFile writes an override file into the working directory of an existing scenario. The file is
+// removed once the test finishes. See WithOverrideFile. The scenario must work on a copy of the module, so it must
+// have been created with WithParallel or another override option.
+//
+// Parameters:
+//   - name: The name of the file (e.g.: "provider_override.tf").
+//   - content: The HCL content of the file.
+//
+// Returns:
+//   - error: An error if the file is not a valid override file, could not be written, or the scenario works on the
+//     module sources.
+func (c *Client) InjectOverrideFile(name, content string) error {
+	if err := validateOverrideFile(name, []byte(content)); err != nil {
+		return err
+	}
+
+	if !c.isolated {
+		return fmt.Errorf("the scenario works on the module sources in %s, create it with WithParallel to inject override files",
+			c.GetTerraformOptions().TerraformDir)
+	}
+
+	file := overrideFile{name: name, content: []byte(content)}
+
+	if err := writeOverrideFile(c.t, c.GetTerraformOptions().TerraformDir, file); err != nil {
+		return err
+	}
+
+	c.Stg.trackGeneratedFile(file)
+
+	return nil
+}
+
+// AWSProviderOverride renders an override of the AWS provider that sends the requests of the given services to a
+// local stand-in, with fake credentials and without the checks that need a real AWS account. The region and
+// the other arguments of the module's provider block are kept.
+//
+// Parameters:
+//   - endpoint: The endpoint of the stand-in (e.g.: "http://localhost:4566").
+//   - services: The services whose endpoint is overridden (e.g.: "s3", "sqs").
+//
+// Returns:
+//   - string: The HCL content of the override file.
+//
+// Example:
+//
+//	err := s.InjectOverrideFile(scenario.AWSProviderOverrideFile,
+//	    scenario.AWSProviderOverride("http://localhost:4566", "s3", "sqs"))
+func AWSProviderOverride(endpoint string, services ...string) string {
+	file := hclwrite.NewEmptyFile()
+	provider := file.Body().AppendNewBlock("provider", []string{"aws"}).Body()
+
+	provider.SetAttributeValue("access_key", cty.StringVal(AWSTestCredential))
+	provider.SetAttributeValue("secret_key", cty.StringVal(AWSTestCredential))
+	provider.SetAttributeValue("skip_credentials_validation", cty.True)
+	provider.SetAttributeValue("skip_metadata_api_check", cty.True)
+	provider.SetAttributeValue("skip_requesting_account_id", cty.True)
+
+	sorted := append([]string(nil), services...)
+	sort.Strings(sorted)
+
+	if slices.Contains(sorted, "s3") {
+		// Stand-ins do not resolve virtual-hosted bucket names.
+		provider.SetAttributeValue("s3_use_path_style", cty.True)
+	}
+
+	if len(sorted) > 0 {
+		endpoints := provider.AppendNewBlock("endpoints", nil).Body()
+		for _, service := range sorted {
+			endpoints.SetAttributeValue(service, cty.StringVal(endpoint))
+		}
+	}
+
+	return string(file.Bytes())
+}
+
+// GetStateFilePath returns the path of the state file of the scenario. It is only known when the local backend
+// is forced through WithLocalBackend (or WithBackendOverride with the "local" type).
+//
+// Returns:
+//   - string: The path of the state file, or an empty string if it is not known.
+func (c *Client) GetStateFilePath() string {
+	return c.stateFilePath
+}
+
+// renderBackendOverride renders an override file that replaces the backend of the module.
+//
+// Parameters:
+//   - backendType: The type of the backend.
+//   - config: The arguments of the backend block.
+//
+// Returns:
+//   - []byte: The content of the override file.
+//   - error: An error if an argument has an unsupported type.
+func renderBackendOverride(backendType string, config map[string]interface{}) ([]byte, error) {
+	file := hclwrite.NewEmptyFile()
+	backend := file.Body().AppendNewBlock("terraform", nil).Body().AppendNewBlock("backend", []string{backendType}).Body()
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, err := toCtyValue(config[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for the argument %s of the %s backend: %v", key, backendType, err)
+		}
+
+		backend.SetAttributeValue(key, value)
+	}
+
+	return file.Bytes(), nil
+}
+
+// toCtyValue converts a Go value into a cty value, for the types that backend arguments use.
+func toCtyValue(value interface{}) (cty.Value, error) {
+	switch v := value.(type) {
+	case string:
+		return cty.StringVal(v), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case int:
+		return cty.NumberIntVal(int64(v)), nil
+	case int64:
+		return cty.NumberIntVal(v), nil
+	case float64:
+		return cty.NumberFloatVal(v), nil
+	default:
+		return cty.NilVal, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// writeOverrideFile writes an override file into the working directory and removes it once the test finishes.
+// The file starts with generatedFileHeader. Existing files are only overwritten if they start with it too, i.e.
+// if a previous run that did not finish left them behind; the others belong to the module under test.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - dir: The working directory of the scenario.
+//   - file: The override file.
+//
+// Returns:
+//   - error: An error if the file is not an override file, already exists or could not be written.
+func writeOverrideFile(t *testing.T, dir string, file overrideFile) error {
+	if err := validateOverrideFileName(file.name); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, file.name)
+
+	existing, err := os.ReadFile(path)
+	if err == nil && !isGeneratedFile(existing) {
+		return fmt.Errorf("the override file %s already exists and would be overwritten", path)
+	}
+
+	if err == nil {
+		t.Logf("Replacing the override file %s left behind by a previous run", path)
+	}
+
+	content := append([]byte(generatedFileHeader), file.content...)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write the override file %s: %v", path, err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			t.Logf("Failed to remove the override file %s: %v", path, err)
+		}
+	})
+
+	return nil
+}
+
+// hasOverrideFiles reports whether the scenario writes override files into its working directory.
+func (o *Options) hasOverrideFiles() bool {
+	return o.backend != nil || len(o.overrideFiles) > 0
+}
+
+// isSameDir reports whether both paths resolve to the same directory.
+func isSameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
+
+// trackGeneratedFile records a file written by tftest into the working directory, so the checks of the module
+// ignore it (see FmtCheckStage), and the stages working on another checkout get it too (see UpgradeFromRefStage).
+func (c *StageClient) trackGeneratedFile(file overrideFile) {
+	if c.generatedFiles == nil {
+		c.generatedFiles = map[string]overrideFile{}
+	}
+
+	c.generatedFiles[file.name] = file
+}
+
+// isGeneratedFile reports whether the content is the one of a file written by tftest.
+func isGeneratedFile(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(generatedFileHeader))
+}
+
+// validateOverrideFile checks that the file is a Terraform override file, and that its content is valid HCL.
+//
+// Parameters:
+//   - name: The name of the file.
+//   - content: The content of the file.
+//
+// Returns:
+//   - error: An error describing why the file is not valid.
+func validateOverrideFile(name string, content []byte) error {
+	if err := validateOverrideFileName(name); err != nil {
+		return err
+	}
+
+	if _, diags := hclsyntax.ParseConfig(content, name, hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+		return fmt.Errorf("the override file %s is not valid HCL: %v", name, diags)
+	}
+
+	return nil
+}
+
+// validateOverrideFileName checks that the name is the one of a Terraform override file, in the working directory.
+func validateOverrideFileName(name string) error {
+	if name != "override.tf" && !strings.HasSuffix(name, "_override.tf") || filepath.Base(name) != name {
+		return fmt.Errorf("the file %s is not a Terraform override file (override.tf or *_override.tf)", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBackendOverride(t *testing.T) {
+	content, err := renderBackendOverride("s3", map[string]interface{}{
+		"region":      "us-east-1",
+		"bucket":      "my-test-states",
+		"encrypt":     true,
+		"max_retries": 5,
+	})
+	require.NoError(t, err)
+
+	expected := `terraform {
+  backend "s3" {
+    bucket      = "my-test-states"
+    encrypt     = true
+    max_retries = 5
+    region      = "us-east-1"
+  }
+}
+`
+	assert.Equal(t, expected, string(content))
+
+	content, err = renderBackendOverride(LocalBackend, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "terraform {\n  backend \"local\" {\n  }\n}\n", string(content))
+
+	_, err = renderBackendOverride("s3", map[string]interface{}{"bucket": []string{"a"}})
+	assert.ErrorContains(t, err, "unsupported type []string")
+}
+
+func TestLocalStatePath(t *testing.T) {
+	dir := filepath.Join("tmp", "module")
+
+	assert.Equal(t, filepath.Join(dir, "terraform.tfstate"), localStatePath(dir, nil, ""))
+	assert.Equal(t, filepath.Join(dir, "terraform.tfstate"), localStatePath(dir, nil, DefaultWorkspace))
+	assert.Equal(t, filepath.Join(dir, "states", "test.tfstate"), localStatePath(dir, map[string]interface{}{"path": "states/test.tfstate"}, ""))
+	assert.Equal(t, "/var/state.tfstate", localStatePath(dir, map[string]interface{}{"path": "/var/state.tfstate"}, ""))
+	assert.Equal(t, filepath.Join(dir, "terraform.tfstate.d", "staging", "terraform.tfstate"), localStatePath(dir, nil, "staging"))
+	assert.Equal(t, filepath.Join(dir, "ws", "staging", "terraform.tfstate"), localStatePath(dir, map[string]interface{}{"workspace_dir": "ws"}, "staging"))
+}
+
+func TestWriteOverrideFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, BackendOverrideFile)
+
+	t.Run("written and removed", func(t *testing.T) {
+		require.NoError(t, writeOverrideFile(t, dir, overrideFile{name: BackendOverrideFile, content: []byte("terraform {}\n")}))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, generatedFileHeader+"terraform {}\n", string(content))
+	})
+
+	assert.NoFileExists(t, path)
+
+	t.Run("left behind by a previous run", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(generatedFileHeader+"terraform {}\n"), 0o600))
+		require.NoError(t, writeOverrideFile(t, dir, overrideFile{name: BackendOverrideFile, content: []byte("locals {}\n")}))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, generatedFileHeader+"locals {}\n", string(content))
+	})
+
+	t.Run("owned by the module", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("terraform {}\n"), 0o600))
+		assert.ErrorContains(t, writeOverrideFile(t, dir, overrideFile{name: BackendOverrideFile}), "already exists")
+		require.NoError(t, os.Remove(path))
+	})
+
+	for _, name := range []string{"main.tf", "backend_override.tf.bak", "../escape_override.tf"} {
+		assert.Errorf(t, writeOverrideFile(t, dir, overrideFile{name: name}), "%s is not an override file", name)
+	}
+
+	assert.NoError(t, writeOverrideFile(t, dir, overrideFile{name: "override.tf"}))
+}
+
+func TestAWSProviderOverride(t *testing.T) {
+	expected := `provider "aws" {
+  access_key                  = "test"
+  secret_key                  = "test"
+  skip_credentials_validation = true
+  skip_metadata_api_check     = true
+  skip_requesting_account_id  = true
+  s3_use_path_style           = true
+  endpoints {
+    s3  = "http://localhost:4566"
+    sqs = "http://localhost:4566"
+  }
+}
+`
+	override := AWSProviderOverride("http://localhost:4566", "sqs", "s3")
+
+	assert.Equal(t, expected, override)
+	assert.NoError(t, validateOverrideFile(AWSProviderOverrideFile, []byte(override)))
+	assert.NotContains(t, AWSProviderOverride("http://localhost:4566"), "endpoints")
+}
+
+func TestValidateOverrideFile(t *testing.T) {
+	assert.NoError(t, validateOverrideFile("data_override.tf", []byte(`data "aws_caller_identity" "current" {}`)))
+	assert.ErrorContains(t, validateOverrideFile("data_override.tf", []byte(`data "aws_caller_identity" {`)), "not valid HCL")
+	assert.ErrorContains(t, validateOverrideFile("data.tf", nil), "not a Terraform override file")
+}
+
+func TestIsSameDir(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.True(t, isSameDir(dir, filepath.Join(dir, "module", "..")))
+	assert.False(t, isSameDir(dir, filepath.Join(dir, "module")))
+}
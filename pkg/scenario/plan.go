@@ -0,0 +1,316 @@
+package scenario
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Plan represents the result of a single plan stage. It carries the parsed plan (see terraform.PlanStruct,
+// which exposes the tfjson.Plan as RawPlan) and the raw JSON returned by 'terraform show -json', so that
+// several assertions can be made against the same plan without running init and plan again.
+type Plan struct {
+	*terraform.PlanStruct
+
+	// JSON is the raw JSON representation of the plan.
+	JSON string
+
+	// Diagnostics are the warnings reported while planning. It is empty when the plan was not produced by a stage.
+	Diagnostics Diagnostics
+}
+
+// NewPlanFromJSON creates a new Plan from the JSON representation of a Terraform plan.
+//
+// Parameters:
+//   - jsonPlan: The JSON output of 'terraform show -json' for a plan file.
+//
+// Returns:
+//   - *Plan: The parsed plan.
+//   - error: An error if the plan could not be parsed.
+func NewPlanFromJSON(jsonPlan string) (*Plan, error) {
+	planStruct, err := terraform.ParsePlanJSON(jsonPlan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the terraform plan: %v", err)
+	}
+
+	return &Plan{
+		PlanStruct: planStruct,
+		JSON:       jsonPlan,
+	}, nil
+}
+
+// ChangedResources returns the resource changes of the plan that are not a no-op.
+//
+// Returns:
+//   - []*tfjson.ResourceChange: The resource changes that will perform an action.
+func (p *Plan) ChangedResources() []*tfjson.ResourceChange {
+	var changed []*tfjson.ResourceChange
+
+	for _, change := range p.RawPlan.ResourceChanges {
+		if change.Change == nil || change.Change.Actions.NoOp() {
+			continue
+		}
+
+		changed = append(changed, change)
+	}
+
+	return changed
+}
+
+// CheckResourcesChanges checks if the specified resources have the expected changes
+// The check function is used to determine if the resource has the expected change
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses to check.
+//   - check: The function that determines if the resource has the expected change.
+//   - failMsg: The failure message, formatted with the resource address.
+func (p *Plan) CheckResourcesChanges(t *testing.T, resources []string, check func(tfjson.Actions) bool, failMsg string) {
+	resourceFound := make(map[string]bool)
+	for _, resource := range resources {
+		resourceFound[resource] = false
+	}
+
+	for _, change := range p.RawPlan.ResourceChanges {
+		if _, exists := resourceFound[change.Address]; exists && check(change.Change.Actions) {
+			resourceFound[change.Address] = true
+		}
+	}
+
+	for resource, found := range resourceFound {
+		require.Truef(t, found, failMsg, resource)
+	}
+}
+
+// AssertExpectedChanges checks for the expected number of resources to be created.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - expectedChanges: The expected number of changes.
+func (p *Plan) AssertExpectedChanges(t *testing.T, expectedChanges int) {
+	for _, change := range p.RawPlan.ResourceChanges {
+		if change.Change.Actions.Create() {
+			expectedChanges--
+		}
+	}
+
+	require.Equalf(t, 0, expectedChanges, "Expected and actual changes do not match")
+}
+
+// AssertDetailedExpectedChanges checks for the expected number of additions, deletions, and updates.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - expectedAdds: The expected number of additions.
+//   - expectedDeletes: The expected number of deletions.
+//   - expectedUpdates: The expected number of updates.
+func (p *Plan) AssertDetailedExpectedChanges(t *testing.T, expectedAdds, expectedDeletes, expectedUpdates int) {
+	actualAdds, actualDeletes, actualUpdates := 0, 0, 0
+	for _, change := range p.RawPlan.ResourceChanges {
+		switch {
+		case change.Change.Actions.Create():
+			actualAdds++
+		case change.Change.Actions.Delete():
+			actualDeletes++
+		case change.Change.Actions.Update():
+			actualUpdates++
+		}
+	}
+
+	require.Equalf(t, expectedAdds, actualAdds, "Expected and actual additions do not match")
+	require.Equalf(t, expectedDeletes, actualDeletes, "Expected and actual deletions do not match")
+	require.Equalf(t, expectedUpdates, actualUpdates, "Expected and actual updates do not match")
+}
+
+// AssertAnySortOfChanges checks that the plan has at least one resource change, no-ops included.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertAnySortOfChanges(t *testing.T) {
+	require.NotEmptyf(t, p.RawPlan.ResourceChanges, "No changes found: %s", p.JSON)
+}
+
+// AssertNoChanges checks that the plan has no resource changes, no-ops included.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertNoChanges(t *testing.T) {
+	require.Emptyf(t, p.RawPlan.ResourceChanges, "Changes found: %s", p.JSON)
+}
+
+// AssertAnyChangesIgnoringNoOps checks that the plan has at least one resource that is not a no-op.
+// Unlike AssertAnySortOfChanges, the resources that Terraform lists without an action are not counted.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertAnyChangesIgnoringNoOps(t *testing.T) {
+	require.NotEmptyf(t, p.ChangedResources(), "No changes found: %s", p.JSON)
+}
+
+// AssertNoChangesIgnoringNoOps checks that every resource of the plan is a no-op.
+// Unlike AssertNoChanges, the resources that Terraform lists without an action are accepted.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertNoChangesIgnoringNoOps(t *testing.T) {
+	changed := p.ChangedResources()
+
+	addresses := make([]string, 0, len(changed))
+	for _, change := range changed {
+		addresses = append(addresses, fmt.Sprintf("%s (%v)", change.Address, change.Change.Actions))
+	}
+
+	require.Emptyf(t, addresses, "Changes found: %v", addresses)
+}
+
+// AssertResourcesThatWillChange checks that the specified resources will change.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses that are expected to change.
+func (p *Plan) AssertResourcesThatWillChange(t *testing.T, resources []string) {
+	resourceChangeFound := p.resourcesWithChanges(resources)
+
+	for resource, changed := range resourceChangeFound {
+		require.Truef(t, changed, "Resource %s did not change but was expected to", resource)
+	}
+}
+
+// AssertResourcesThatShouldNotChange checks that the specified resources should not change.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses that are expected not to change.
+func (p *Plan) AssertResourcesThatShouldNotChange(t *testing.T, resources []string) {
+	resourceChangeFound := p.resourcesWithChanges(resources)
+
+	for resource, changed := range resourceChangeFound {
+		require.Falsef(t, changed, "Resource %s changed but was expected not to", resource)
+	}
+}
+
+// AssertResourcesExpectedToBeCreated checks that the specified resources are expected to be created.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses that are expected to be created.
+func (p *Plan) AssertResourcesExpectedToBeCreated(t *testing.T, resources []string) {
+	p.CheckResourcesChanges(t, resources, func(action tfjson.Actions) bool {
+		return action.Create()
+	}, "Resource %s was not marked to be created but was expected to")
+}
+
+// AssertResourcesExpectedToBeDeleted checks that the specified resources are expected to be deleted.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses that are expected to be deleted.
+func (p *Plan) AssertResourcesExpectedToBeDeleted(t *testing.T, resources []string) {
+	p.CheckResourcesChanges(t, resources, func(action tfjson.Actions) bool {
+		return action.Delete()
+	}, "Resource %s was not marked to be deleted but was expected to")
+}
+
+// AssertNoDestroyOrReplace checks that the plan does not delete nor replace any resource. It is typically used
+// after an upgrade, where existing infrastructure is expected to be updated in place.
+//
+// Parameters:
+//   - t: The testing instance.
+func (p *Plan) AssertNoDestroyOrReplace(t *testing.T) {
+	var destroyed []string
+	for _, change := range p.ChangedResources() {
+		if change.Change.Actions.Delete() || change.Change.Actions.Replace() {
+			destroyed = append(destroyed, fmt.Sprintf("%s (%v)", change.Address, change.Change.Actions))
+		}
+	}
+
+	assert.Emptyf(t, destroyed, "The following resources would be destroyed or replaced: %v", destroyed)
+}
+
+// AssertResourcesExpectedToBeUpdated checks that the specified resources are expected to be updated.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resources: A list of resource addresses that are expected to be updated.
+func (p *Plan) AssertResourcesExpectedToBeUpdated(t *testing.T, resources []string) {
+	p.CheckResourcesChanges(t, resources, func(action tfjson.Actions) bool {
+		return action.Update()
+	}, "Resource %s was not marked to be updated but was expected to")
+}
+
+// AssertVariableValue checks that the specified variable has the expected value.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - variable: The name of the variable to check.
+//   - expectedValue: The expected value of the variable.
+func (p *Plan) AssertVariableValue(t *testing.T, variable, expectedValue string) {
+	variableFromPlan, found := p.RawPlan.Variables[variable]
+	require.Truef(t, found, "Variable %s was not found in the plan", variable)
+	require.NotNilf(t, variableFromPlan, "Variable %s was found in the plan but was nil", variable)
+
+	actualValue := variableFromPlan.Value
+	compareValues(t, actualValue, expectedValue, variable)
+}
+
+// AssertJSONWithJSONPath performs JSON path assertions against the JSON representation of the plan.
+//
+// Parameters:
+//   - t: The testing object.
+//   - testCases: An array of JSON path test cases.
+func (p *Plan) AssertJSONWithJSONPath(t *testing.T, testCases []JSONPathTestCases) {
+	assertJSONWithJSONPath(t, []byte(p.JSON), testCases)
+}
+
+// resourcesWithChanges returns, for each of the given resources, whether the plan will create, delete or update it.
+func (p *Plan) resourcesWithChanges(resources []string) map[string]bool {
+	resourceChangeFound := make(map[string]bool)
+	for _, resource := range resources {
+		resourceChangeFound[resource] = false
+	}
+
+	for _, change := range p.RawPlan.ResourceChanges {
+		if _, exists := resourceChangeFound[change.Address]; exists {
+			if change.Change.Actions.Create() || change.Change.Actions.Delete() || change.Change.Actions.Update() {
+				resourceChangeFound[change.Address] = true
+			}
+		}
+	}
+
+	return resourceChangeFound
+}
+
+// assertJSONWithJSONPath runs each JSON path test case against the given JSON document.
+//
+// Parameters:
+//   - t: The testing object.
+//   - jsonDoc: The JSON document to query.
+//   - testCases: An array of JSON path test cases.
+func assertJSONWithJSONPath(t *testing.T, jsonDoc []byte, testCases []JSONPathTestCases) {
+	for _, testCase := range testCases {
+		t.Run(testCase.TestName, func(t *testing.T) {
+			var result []interface{}
+			k8s.UnmarshalJSONPath(t, jsonDoc, testCase.JSONPathToCompare, &result)
+			assert.NotNil(t, result)
+
+			// if expected is slice then it's ok to compare entire slice
+			if reflect.TypeOf(testCase.ExpectedValue).Kind() == reflect.Slice {
+				assert.ObjectsAreEqual(testCase.ExpectedValue, result)
+			} else {
+				// work on the result[0]
+				t.Logf("result returned raw: %v", result)
+				v := result[0]
+				if !testCase.AllowDifferentType {
+					assert.Equal(t, reflect.TypeOf(testCase.ExpectedValue).Kind(), reflect.TypeOf(v).Kind())
+				}
+				applyTestType(t, testCase.TestType, v, testCase.ExpectedValue, fmt.Sprintf("JSONPATH query: %s", testCase.JSONPathToCompare))
+			}
+		})
+	}
+}
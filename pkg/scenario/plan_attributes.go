@@ -0,0 +1,122 @@
+package scenario
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// ResourceChange returns the change planned for the resource with the given address.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address (e.g.: "aws_s3_bucket.this", "module.foo.aws_s3_bucket.this[0]").
+//
+// Returns:
+//   - *tfjson.Change: The planned change of the resource.
+func (p *Plan) ResourceChange(t *testing.T, address string) *tfjson.Change {
+	resourceChange, found := p.ResourceChangesMap[address]
+	require.Truef(t, found, "Resource %s was not found in the plan", address)
+	require.NotNilf(t, resourceChange.Change, "Resource %s was found in the plan but has no change", address)
+
+	return resourceChange.Change
+}
+
+// AssertResourceAttributeBefore checks the value that the attribute of the resource has before the change.
+// Nested blocks and lists are deeply compared, and numbers are compared regardless of their Go type.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path (e.g.: "versioning.0.enabled" or "versioning[0].enabled").
+//   - expected: The expected value.
+func (p *Plan) AssertResourceAttributeBefore(t *testing.T, address, path string, expected interface{}) {
+	assertAttributeValue(t, p.ResourceChange(t, address).Before, address, path, expected, "before")
+}
+
+// AssertResourceAttributeAfter checks the value that the attribute of the resource will have after the change.
+// Nested blocks and lists are deeply compared, and numbers are compared regardless of their Go type.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path (e.g.: "versioning.0.enabled" or "versioning[0].enabled").
+//   - expected: The expected value.
+func (p *Plan) AssertResourceAttributeAfter(t *testing.T, address, path string, expected interface{}) {
+	change := p.ResourceChange(t, address)
+	require.Falsef(t, isAttributeMarked(change.AfterUnknown, path),
+		"Attribute %s of resource %s is known only after apply", path, address)
+
+	assertAttributeValue(t, change.After, address, path, expected, "after")
+}
+
+// AssertResourceAttributeChanged checks that the value of the attribute of the resource changes in the plan.
+// It fails if the value is known only after apply, since whether it changes cannot be told at plan time; use
+// AssertResourceAttributeUnknown for these attributes.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+func (p *Plan) AssertResourceAttributeChanged(t *testing.T, address, path string) {
+	change := p.ResourceChange(t, address)
+	require.Falsef(t, isAttributeMarked(change.AfterUnknown, path),
+		"Attribute %s of resource %s is known only after apply, so it cannot be told whether it changes", path, address)
+
+	before, _ := lookupAttribute(change.Before, path)
+	after, _ := lookupAttribute(change.After, path)
+	require.NotEqualf(t, before, after, "Attribute %s of resource %s was expected to change but it is %v before and after", path, address, after)
+}
+
+// AssertResourceAttributeUnknown checks that the attribute of the resource will be known only after apply.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+func (p *Plan) AssertResourceAttributeUnknown(t *testing.T, address, path string) {
+	require.Truef(t, isAttributeMarked(p.ResourceChange(t, address).AfterUnknown, path),
+		"Attribute %s of resource %s was expected to be known only after apply", path, address)
+}
+
+// AssertResourceAttributeKnown checks that the value of the attribute of the resource is known at plan time.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+func (p *Plan) AssertResourceAttributeKnown(t *testing.T, address, path string) {
+	require.Falsef(t, isAttributeMarked(p.ResourceChange(t, address).AfterUnknown, path),
+		"Attribute %s of resource %s was expected to be known at plan time", path, address)
+}
+
+// AssertResourceAttributeSensitive checks that the attribute of the resource is marked as sensitive after the change.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+func (p *Plan) AssertResourceAttributeSensitive(t *testing.T, address, path string) {
+	require.Truef(t, isAttributeMarked(p.ResourceChange(t, address).AfterSensitive, path),
+		"Attribute %s of resource %s was expected to be sensitive", path, address)
+}
+
+// assertAttributeValue looks up the attribute path in the value and deeply compares it with the expected value.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - value: The value holding the attribute (e.g.: the 'after' value of a change, or the values of a state resource).
+//   - address: The resource address, used in the failure messages.
+//   - path: The attribute path.
+//   - expected: The expected value.
+//   - kind: A description of the value (e.g.: "before", "after"), used in the failure messages.
+func assertAttributeValue(t *testing.T, value interface{}, address, path string, expected interface{}, kind string) {
+	actual, found := lookupAttribute(value, path)
+	require.Truef(t, found, "Attribute %s was not found in the %s value of resource %s", path, kind, address)
+
+	normalizedExpected, err := normalizeJSONValue(expected)
+	require.NoErrorf(t, err, "Failed to normalize the expected value of attribute %s", path)
+
+	require.Equalf(t, normalizedExpected, actual, "Attribute %s of resource %s does not have the expected %s value", path, address, kind)
+}
@@ -0,0 +1,43 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNoOpPlanJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.5",
+  "resource_changes": [
+    {
+      "address": "random_id.this",
+      "type": "random_id",
+      "name": "this",
+      "provider_name": "registry.terraform.io/hashicorp/random",
+      "change": {
+        "actions": ["no-op"],
+        "before": {"byte_length": 8},
+        "after": {"byte_length": 8}
+      }
+    }
+  ]
+}`
+
+func TestChangedResources(t *testing.T) {
+	plan, err := NewPlanFromJSON(testNoOpPlanJSON)
+	require.NoError(t, err)
+
+	assert.Len(t, plan.RawPlan.ResourceChanges, 1)
+	assert.Empty(t, plan.ChangedResources())
+}
+
+func TestPlanAssertionsOnNoOps(t *testing.T) {
+	plan, err := NewPlanFromJSON(testNoOpPlanJSON)
+	require.NoError(t, err)
+
+	// The no-op entry counts as a change for the assertions that look at every resource change.
+	plan.AssertAnySortOfChanges(t)
+	plan.AssertNoChangesIgnoringNoOps(t)
+}
@@ -0,0 +1,263 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	snapshotUnknownValue   = "(known after apply)"
+	snapshotSensitiveValue = "(sensitive value)"
+	snapshotTimestampValue = "(timestamp)"
+	snapshotUUIDValue      = "(uuid)"
+	snapshotIDValue        = "(id)"
+	snapshotRandomValue    = "(random)"
+)
+
+var (
+	timestampRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	uuidRegex      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// randomResultAttributes are the attributes of the resources of the random provider that hold generated values.
+var randomResultAttributes = map[string]bool{
+	"result":      true,
+	"b64_std":     true,
+	"b64_url":     true,
+	"hex":         true,
+	"dec":         true,
+	"bcrypt_hash": true,
+}
+
+// planSnapshot is the normalized representation of a plan that is stored in the golden files.
+type planSnapshot struct {
+	ResourceChanges []resourceChangeSnapshot  `json:"resource_changes"`
+	OutputChanges   map[string]changeSnapshot `json:"output_changes,omitempty"`
+}
+
+// resourceChangeSnapshot is the normalized representation of a resource change.
+type resourceChangeSnapshot struct {
+	Address string `json:"address"`
+	changeSnapshot
+}
+
+// changeSnapshot is the normalized representation of a change.
+type changeSnapshot struct {
+	Actions []string    `json:"actions"`
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
+}
+
+// SnapshotPath returns the default path of the golden file for the given snapshot name.
+//
+// Parameters:
+//   - name: The name of the snapshot.
+//
+// Returns:
+//   - string: The path of the golden file, relative to the test package.
+func SnapshotPath(name string) string {
+	return filepath.Join(DefaultSnapshotDir, name+".golden.json")
+}
+
+// Snapshot returns the normalized JSON representation of the plan. Unknown values, sensitive values,
+// timestamps, UUIDs, IDs and the values generated by the random provider are replaced by placeholders,
+// and the Terraform and provider versions are left out, so the snapshot is stable across runs.
+//
+// Returns:
+//   - []byte: The normalized JSON representation of the plan.
+//   - error: An error if the plan could not be normalized.
+func (p *Plan) Snapshot() ([]byte, error) {
+	snapshot := planSnapshot{
+		ResourceChanges: []resourceChangeSnapshot{},
+	}
+
+	for _, rc := range p.RawPlan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+
+		snapshot.ResourceChanges = append(snapshot.ResourceChanges, resourceChangeSnapshot{
+			Address:        rc.Address,
+			changeSnapshot: normalizeChange(rc.Change, strings.HasPrefix(rc.Type, "random_")),
+		})
+	}
+
+	sort.Slice(snapshot.ResourceChanges, func(i, j int) bool {
+		return snapshot.ResourceChanges[i].Address < snapshot.ResourceChanges[j].Address
+	})
+
+	if len(p.RawPlan.OutputChanges) > 0 {
+		snapshot.OutputChanges = make(map[string]changeSnapshot, len(p.RawPlan.OutputChanges))
+		for name, change := range p.RawPlan.OutputChanges {
+			snapshot.OutputChanges[name] = normalizeChange(change, false)
+		}
+	}
+
+	out, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the plan snapshot: %v", err)
+	}
+
+	return append(out, '\n'), nil
+}
+
+// AssertMatchesSnapshot compares the normalized plan against the golden file at the given path.
+// When the UPDATE_SNAPSHOTS environment variable is set to "1" or "true", the golden file is (re)written instead.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - snapshotPath: The path of the golden file. See SnapshotPath for the default location.
+func (p *Plan) AssertMatchesSnapshot(t *testing.T, snapshotPath string) {
+	actual, err := p.Snapshot()
+	require.NoErrorf(t, err, "Failed to create the snapshot of the plan")
+
+	if shouldUpdateSnapshots() {
+		require.NoErrorf(t, os.MkdirAll(filepath.Dir(snapshotPath), 0o755), "Failed to create the snapshot directory for %s", snapshotPath)
+		require.NoErrorf(t, os.WriteFile(snapshotPath, actual, 0o644), "Failed to write the snapshot %s", snapshotPath)
+		t.Logf("Snapshot updated: %s", snapshotPath)
+
+		return
+	}
+
+	expected, err := os.ReadFile(snapshotPath)
+	if os.IsNotExist(err) {
+		require.Failf(t, "Snapshot not found", "The snapshot %s does not exist, run the test with %s=1 to create it", snapshotPath, SnapshotUpdateEnvVar)
+	}
+
+	require.NoErrorf(t, err, "Failed to read the snapshot %s", snapshotPath)
+	require.Equalf(t, string(expected), string(actual),
+		"The plan does not match the snapshot %s, run the test with %s=1 to update it", snapshotPath, SnapshotUpdateEnvVar)
+}
+
+// PlanStageMatchesSnapshot plans the Terraform stage and compares the normalized plan against the golden file.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//   - snapshotPath: The path of the golden file. See SnapshotPath for the default location.
+func (c *StageClient) PlanStageMatchesSnapshot(t *testing.T, options *terraform.Options, snapshotPath string) {
+	c.PlanAndShowStage(t, options).AssertMatchesSnapshot(t, snapshotPath)
+}
+
+// shouldUpdateSnapshots returns true if the golden files should be rewritten.
+func shouldUpdateSnapshots() bool {
+	update, err := strconv.ParseBool(os.Getenv(SnapshotUpdateEnvVar))
+	return err == nil && update
+}
+
+// normalizeChange normalizes the actions and the before/after values of a change.
+func normalizeChange(change *tfjson.Change, isRandom bool) changeSnapshot {
+	actions := make([]string, 0, len(change.Actions))
+	for _, action := range change.Actions {
+		actions = append(actions, string(action))
+	}
+
+	return changeSnapshot{
+		Actions: actions,
+		Before:  normalizeValue(change.Before, nil, change.BeforeSensitive, "", isRandom),
+		After:   normalizeValue(change.After, change.AfterUnknown, change.AfterSensitive, "", isRandom),
+	}
+}
+
+// normalizeValue walks a value of a change, replacing the unknown, sensitive and volatile values by placeholders.
+//
+// Parameters:
+//   - value: The value to normalize.
+//   - unknown: The matching after_unknown structure (or nil).
+//   - sensitive: The matching before_sensitive/after_sensitive structure (or nil).
+//   - key: The attribute name of the value, if any.
+//   - isRandom: Whether the value belongs to a resource of the random provider.
+//
+// Returns:
+//   - interface{}: The normalized value.
+func normalizeValue(value, unknown, sensitive interface{}, key string, isRandom bool) interface{} {
+	if b, ok := unknown.(bool); ok && b {
+		return snapshotUnknownValue
+	}
+
+	if b, ok := sensitive.(bool); ok && b {
+		return snapshotSensitiveValue
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		unknownMap, _ := unknown.(map[string]interface{})
+		sensitiveMap, _ := sensitive.(map[string]interface{})
+
+		normalized := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			normalized[k] = normalizeValue(nested, unknownMap[k], sensitiveMap[k], k, isRandom)
+		}
+
+		// Unknown attributes are not part of the value, but they are relevant for the snapshot.
+		for k, u := range unknownMap {
+			if _, exists := normalized[k]; !exists {
+				if b, ok := u.(bool); ok && b {
+					normalized[k] = snapshotUnknownValue
+				}
+			}
+		}
+
+		return normalized
+	case []interface{}:
+		unknownList, _ := unknown.([]interface{})
+		sensitiveList, _ := sensitive.([]interface{})
+
+		normalized := make([]interface{}, len(v))
+		for i, nested := range v {
+			normalized[i] = normalizeValue(nested, elementAt(unknownList, i), elementAt(sensitiveList, i), "", isRandom)
+		}
+
+		return normalized
+	case nil:
+		if b, ok := unknown.(map[string]interface{}); ok && len(b) > 0 {
+			return normalizeValue(map[string]interface{}{}, unknown, sensitive, key, isRandom)
+		}
+
+		return nil
+	default:
+		return normalizeScalar(v, key, isRandom)
+	}
+}
+
+// normalizeScalar replaces volatile scalar values (IDs, timestamps, UUIDs and random results) by placeholders.
+func normalizeScalar(value interface{}, key string, isRandom bool) interface{} {
+	if key == "id" {
+		return snapshotIDValue
+	}
+
+	if isRandom && randomResultAttributes[key] {
+		return snapshotRandomValue
+	}
+
+	if s, ok := value.(string); ok {
+		switch {
+		case timestampRegex.MatchString(s):
+			return snapshotTimestampValue
+		case uuidRegex.MatchString(s):
+			return snapshotUUIDValue
+		}
+	}
+
+	return value
+}
+
+// elementAt returns the element at the given index of the list, or nil if the index is out of range.
+func elementAt(list []interface{}, i int) interface{} {
+	if i < len(list) {
+		return list[i]
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.7.5",
+  "timestamp": "2024-04-01T10:00:00Z",
+  "resource_changes": [
+    {
+      "address": "random_uuid.this",
+      "type": "random_uuid",
+      "name": "this",
+      "provider_name": "registry.terraform.io/hashicorp/random",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"keepers": null},
+        "after_unknown": {"id": true, "result": true},
+        "before_sensitive": false,
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "random_id.this",
+      "type": "random_id",
+      "name": "this",
+      "provider_name": "registry.terraform.io/hashicorp/random",
+      "change": {
+        "actions": ["no-op"],
+        "before": {"byte_length": 8, "hex": "a1b2c3d4e5f60718", "id": "obLD1OX2Bxg"},
+        "after": {"byte_length": 8, "hex": "a1b2c3d4e5f60718", "id": "obLD1OX2Bxg"},
+        "after_unknown": {},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "aws_s3_bucket.this",
+      "type": "aws_s3_bucket",
+      "name": "this",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["update"],
+        "before": {"bucket": "my-bucket", "created": "2024-04-01T10:00:00Z", "tags": {"owner": "a"}, "secret": "x"},
+        "after": {"bucket": "my-bucket", "created": "2024-04-01T10:00:00Z", "tags": {"owner": "b"}, "secret": "y"},
+        "after_unknown": {"arn": true},
+        "before_sensitive": {"secret": true},
+        "after_sensitive": {"secret": true}
+      }
+    }
+  ]
+}`
+
+const testPlanSnapshot = `{
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.this",
+      "actions": [
+        "update"
+      ],
+      "before": {
+        "bucket": "my-bucket",
+        "created": "(timestamp)",
+        "secret": "(sensitive value)",
+        "tags": {
+          "owner": "a"
+        }
+      },
+      "after": {
+        "arn": "(known after apply)",
+        "bucket": "my-bucket",
+        "created": "(timestamp)",
+        "secret": "(sensitive value)",
+        "tags": {
+          "owner": "b"
+        }
+      }
+    },
+    {
+      "address": "random_id.this",
+      "actions": [
+        "no-op"
+      ],
+      "before": {
+        "byte_length": 8,
+        "hex": "(random)",
+        "id": "(id)"
+      },
+      "after": {
+        "byte_length": 8,
+        "hex": "(random)",
+        "id": "(id)"
+      }
+    },
+    {
+      "address": "random_uuid.this",
+      "actions": [
+        "create"
+      ],
+      "after": {
+        "id": "(known after apply)",
+        "keepers": null,
+        "result": "(known after apply)"
+      }
+    }
+  ]
+}
+`
+
+func TestPlanSnapshot(t *testing.T) {
+	plan, err := NewPlanFromJSON(testPlanJSON)
+	require.NoError(t, err)
+
+	snapshot, err := plan.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, testPlanSnapshot, string(snapshot))
+}
+
+func TestPlanAssertMatchesSnapshot(t *testing.T) {
+	plan, err := NewPlanFromJSON(testPlanJSON)
+	require.NoError(t, err)
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshots", "plan.golden.json")
+
+	t.Setenv(SnapshotUpdateEnvVar, "1")
+	plan.AssertMatchesSnapshot(t, snapshotPath)
+
+	written, err := os.ReadFile(snapshotPath)
+	require.NoError(t, err)
+	assert.Equal(t, testPlanSnapshot, string(written))
+
+	t.Setenv(SnapshotUpdateEnvVar, "")
+	plan.AssertMatchesSnapshot(t, snapshotPath)
+}
+
+func TestSnapshotPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("testdata", "snapshots", "basic.golden.json"), SnapshotPath("basic"))
+}
@@ -2,11 +2,11 @@ package scenario
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/stretchr/testify/assert"
 
 	tfjson "github.com/hashicorp/terraform-json"
@@ -16,7 +16,15 @@ import (
 )
 
 // StageClient represents a client for managing Terraform stages.
-type StageClient struct{}
+type StageClient struct {
+	guard *awsGuard
+
+	// generatedFiles are the files written by tftest into the working directory, by name.
+	generatedFiles map[string]overrideFile
+
+	// backend is the backend forced on the scenario, if any.
+	backend *backendOverride
+}
 
 // TestType represents the type of test to be performed.
 type TestType int
@@ -39,8 +47,18 @@ type JSONPathTestCases struct {
 // Stage defines an interface for managing Terraform stages.
 type Stage interface {
 	DestroyStage(t *testing.T, options *terraform.Options)
+	PlanAndShowStage(t *testing.T, options *terraform.Options) *Plan
+	ValidateStage(t *testing.T, options *terraform.Options) Diagnostics
+	FmtCheckStage(t *testing.T, options *terraform.Options)
 	PlanStage(t *testing.T, options *terraform.Options)
+	PlanStageWithDiagnostics(t *testing.T, options *terraform.Options) Diagnostics
+	PlanExpectError(t *testing.T, options *terraform.Options, expected ExpectedError)
 	ApplyStage(t *testing.T, options *terraform.Options)
+	ApplyStageWithDiagnostics(t *testing.T, options *terraform.Options) Diagnostics
+	ApplyExpectError(t *testing.T, options *terraform.Options, expected ExpectedError)
+	IdempotencyStage(t *testing.T, options *terraform.Options)
+	UpgradeStage(t *testing.T, options *terraform.Options, repoURL, moduleDir string) *Plan
+	UpgradeFromRefStage(t *testing.T, options *terraform.Options, ref, moduleDir string) *Plan
 	PlanStageWithExpectedChanges(t *testing.T, options *terraform.Options, expectedChanges int)
 	PlanStageWithDetailedExpectedChanges(t *testing.T, options *terraform.Options, expectedAdds, expectedDeletes, expectedUpdates int)
 	PlanStageWithAnySortOfChanges(t *testing.T, options *terraform.Options)
@@ -52,58 +70,117 @@ type Stage interface {
 	PlanWithResourcesExpectedToBeUpdated(t *testing.T, options *terraform.Options, resources []string)
 	PlanWithSpecificVariableValueToExpect(t *testing.T, options *terraform.Options, variable, value string)
 	PlanAndAssertJSONWithJSONPath(t *testing.T, options *terraform.Options, testCases []JSONPathTestCases)
+	PlanStageMatchesSnapshot(t *testing.T, options *terraform.Options, snapshotPath string)
+	OutputStage(t *testing.T, options *terraform.Options) *Outputs
+	StateStage(t *testing.T, options *terraform.Options) *State
+	TerragruntRunAllPlanStage(t *testing.T, options *terraform.Options)
+	TerragruntRunAllApplyStage(t *testing.T, options *terraform.Options)
+	TerragruntRunAllDestroyStage(t *testing.T, options *terraform.Options)
 }
 
-// CheckResourcesChanges checks if the specified resources have the expected changes
-// The check function is used to determine if the resource has the expected change
-func (c *StageClient) CheckResourcesChanges(t *testing.T, options *terraform.Options, resources []string, check func(tfjson.Actions) bool, failMsg string) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
+// PlanAndShowStage runs init, plan and show once, and returns the resulting Plan.
+// The returned Plan exposes all the plan assertions, so a scenario can be planned once and asserted many times.
+// If the options do not set a plan file, a temporary one is used.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - *Plan: The plan, parsed and ready to be asserted.
+func (c *StageClient) PlanAndShowStage(t *testing.T, options *terraform.Options) *Plan {
+	planOptions := options
 
-	resourceFound := make(map[string]bool)
-	for _, resource := range resources {
-		resourceFound[resource] = false
-	}
+	if options.PlanFilePath == "" {
+		var err error
+		planOptions, err = options.Clone()
+		require.NoErrorf(t, err, "Failed to clone the terraform options")
 
-	for _, change := range out.RawPlan.ResourceChanges {
-		if _, exists := resourceFound[change.Address]; exists && check(change.Change.Actions) {
-			resourceFound[change.Address] = true
-		}
+		planOptions.PlanFilePath = filepath.Join(t.TempDir(), DefaultPlanOutput)
 	}
 
-	for resource, found := range resourceFound {
-		require.Truef(t, found, failMsg, resource)
-	}
+	diagnostics, out, err := planWithDiagnostics(t, planOptions)
+	requireNoDiagnosticErrors(t, "plan", diagnostics, out, err)
+
+	jsonPlan, err := terraform.ShowE(t, planOptions)
+	require.NoErrorf(t, err, "Failed to show the terraform plan: %s", jsonPlan)
+
+	plan, err := NewPlanFromJSON(jsonPlan)
+	require.NoErrorf(t, err, "Failed to parse the terraform plan")
+
+	plan.Diagnostics = diagnostics
+
+	return plan
+}
+
+// CheckResourcesChanges checks if the specified resources have the expected changes
+// The check function is used to determine if the resource has the expected change
+func (c *StageClient) CheckResourcesChanges(t *testing.T, options *terraform.Options, resources []string, check func(tfjson.Actions) bool, failMsg string) {
+	c.PlanAndShowStage(t, options).CheckResourcesChanges(t, resources, check, failMsg)
 }
 
 // DestroyStage destroys the Terraform stage.
+// If allowed AWS accounts or regions are configured (see WithAllowedAWSAccounts), nothing is destroyed outside of them.
 //
 // Parameters:
 //   - t: The testing instance.
 //   - options: The Terraform options.
 func (c *StageClient) DestroyStage(t *testing.T, options *terraform.Options) {
+	c.requireAllowedAWSTarget(t, options)
+
 	out, err := terraform.DestroyE(t, options)
 	require.NoErrorf(t, err, "Failed to destroy terraform: %s", out)
 }
 
-// PlanStage plans the Terraform stage.
+// PlanStage plans the Terraform stage. If the plan fails, the test fails with one entry per error diagnostic.
 //
 // Parameters:
 //   - t: The testing instance.
 //   - options: The Terraform options.
 func (c *StageClient) PlanStage(t *testing.T, options *terraform.Options) {
-	out, err := terraform.InitAndPlanE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
+	c.PlanStageWithDiagnostics(t, options)
+}
+
+// PlanStageWithDiagnostics plans the Terraform stage like PlanStage, and returns the warnings it reported, so
+// they can be asserted on.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - Diagnostics: The warnings reported by the plan.
+func (c *StageClient) PlanStageWithDiagnostics(t *testing.T, options *terraform.Options) Diagnostics {
+	diagnostics, out, err := planWithDiagnostics(t, options)
+
+	return requireNoDiagnosticErrors(t, "plan", diagnostics, out, err)
 }
 
-// ApplyStage applies the Terraform stage.
+// ApplyStage applies the Terraform stage. If the apply fails, the test fails with one entry per error diagnostic.
+// If allowed AWS accounts or regions are configured (see WithAllowedAWSAccounts), nothing is applied outside of them.
 //
 // Parameters:
 //   - t: The testing instance.
 //   - options: The Terraform options.
 func (c *StageClient) ApplyStage(t *testing.T, options *terraform.Options) {
-	out, err := terraform.InitAndApplyE(t, options)
-	require.NoErrorf(t, err, "Failed to apply terraform: %s", out)
+	c.ApplyStageWithDiagnostics(t, options)
+}
+
+// ApplyStageWithDiagnostics applies the Terraform stage like ApplyStage, and returns the warnings it reported, so
+// they can be asserted on.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - Diagnostics: The warnings reported by the apply.
+func (c *StageClient) ApplyStageWithDiagnostics(t *testing.T, options *terraform.Options) Diagnostics {
+	c.requireAllowedAWSTarget(t, options)
+
+	diagnostics, out, err := applyWithDiagnostics(t, options)
+
+	return requireNoDiagnosticErrors(t, "apply", diagnostics, out, err)
 }
 
 // PlanStageWithExpectedChanges plans the Terraform stage and checks for the expected number of changes.
@@ -113,16 +190,7 @@ func (c *StageClient) ApplyStage(t *testing.T, options *terraform.Options) {
 //   - options: The Terraform options.
 //   - expectedChanges: The expected number of changes.
 func (c *StageClient) PlanStageWithExpectedChanges(t *testing.T, options *terraform.Options, expectedChanges int) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-
-	for _, change := range out.RawPlan.ResourceChanges {
-		if change.Change.Actions.Create() {
-			expectedChanges--
-		}
-	}
-
-	require.Equalf(t, 0, expectedChanges, "Expected and actual changes do not match")
+	c.PlanAndShowStage(t, options).AssertExpectedChanges(t, expectedChanges)
 }
 
 // PlanStageWithDetailedExpectedChanges plans the Terraform stage and checks for the expected number of additions, deletions, and updates.
@@ -134,24 +202,7 @@ func (c *StageClient) PlanStageWithExpectedChanges(t *testing.T, options *terraf
 //   - expectedDeletes: The expected number of deletions.
 //   - expectedUpdates: The expected number of updates.
 func (c *StageClient) PlanStageWithDetailedExpectedChanges(t *testing.T, options *terraform.Options, expectedAdds, expectedDeletes, expectedUpdates int) {
-	planStruct, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", planStruct)
-
-	actualAdds, actualDeletes, actualUpdates := 0, 0, 0
-	for _, change := range planStruct.RawPlan.ResourceChanges {
-		switch {
-		case change.Change.Actions.Create():
-			actualAdds++
-		case change.Change.Actions.Delete():
-			actualDeletes++
-		case change.Change.Actions.Update():
-			actualUpdates++
-		}
-	}
-
-	require.Equalf(t, expectedAdds, actualAdds, "Expected and actual additions do not match")
-	require.Equalf(t, expectedDeletes, actualDeletes, "Expected and actual deletions do not match")
-	require.Equalf(t, expectedUpdates, actualUpdates, "Expected and actual updates do not match")
+	c.PlanAndShowStage(t, options).AssertDetailedExpectedChanges(t, expectedAdds, expectedDeletes, expectedUpdates)
 }
 
 // PlanStageWithAnySortOfChanges plans the Terraform stage and checks for any sort of changes.
@@ -160,10 +211,7 @@ func (c *StageClient) PlanStageWithDetailedExpectedChanges(t *testing.T, options
 //   - t: The testing instance.
 //   - options: The Terraform options.
 func (c *StageClient) PlanStageWithAnySortOfChanges(t *testing.T, options *terraform.Options) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-	require.NotEmptyf(t, out.RawPlan.ResourceChanges, "No changes found: %s", out)
-	require.Truef(t, len(out.RawPlan.ResourceChanges) > 0, "No changes found: %s", out)
+	c.PlanAndShowStage(t, options).AssertAnySortOfChanges(t)
 }
 
 // PlanStageExpectedNoChanges plans the Terraform stage and expects no changes.
@@ -172,9 +220,7 @@ func (c *StageClient) PlanStageWithAnySortOfChanges(t *testing.T, options *terra
 //   - t: The testing instance.
 //   - options: The Terraform options.
 func (c *StageClient) PlanStageExpectedNoChanges(t *testing.T, options *terraform.Options) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-	require.Emptyf(t, out.RawPlan.ResourceChanges, "Changes found: %s", out)
+	c.PlanAndShowStage(t, options).AssertNoChanges(t)
 }
 
 // PlanWithSpecificResourcesThatWillChange plans the Terraform stage and checks that the specified resources will change.
@@ -184,25 +230,7 @@ func (c *StageClient) PlanStageExpectedNoChanges(t *testing.T, options *terrafor
 //   - options: The Terraform options.
 //   - resources: A list of resource addresses that are expected to change.
 func (c *StageClient) PlanWithSpecificResourcesThatWillChange(t *testing.T, options *terraform.Options, resources []string) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-
-	resourceChangeFound := make(map[string]bool)
-	for _, resource := range resources {
-		resourceChangeFound[resource] = false
-	}
-
-	for _, change := range out.RawPlan.ResourceChanges {
-		if _, exists := resourceChangeFound[change.Address]; exists {
-			if change.Change.Actions.Create() || change.Change.Actions.Delete() || change.Change.Actions.Update() {
-				resourceChangeFound[change.Address] = true
-			}
-		}
-	}
-
-	for resource, changed := range resourceChangeFound {
-		require.Truef(t, changed, "Resource %s did not change but was expected to", resource)
-	}
+	c.PlanAndShowStage(t, options).AssertResourcesThatWillChange(t, resources)
 }
 
 // PlanWithSpecificResourcesThatShouldNotChange plans the Terraform stage and checks that the specified resources should not change.
@@ -212,25 +240,7 @@ func (c *StageClient) PlanWithSpecificResourcesThatWillChange(t *testing.T, opti
 //   - options: The Terraform options.
 //   - resources: A list of resource addresses that are expected not to change.
 func (c *StageClient) PlanWithSpecificResourcesThatShouldNotChange(t *testing.T, options *terraform.Options, resources []string) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-
-	resourceChangeFound := make(map[string]bool)
-	for _, resource := range resources {
-		resourceChangeFound[resource] = false
-	}
-
-	for _, change := range out.RawPlan.ResourceChanges {
-		if _, exists := resourceChangeFound[change.Address]; exists {
-			if change.Change.Actions.Create() || change.Change.Actions.Delete() || change.Change.Actions.Update() {
-				resourceChangeFound[change.Address] = true
-			}
-		}
-	}
-
-	for resource, changed := range resourceChangeFound {
-		require.Falsef(t, changed, "Resource %s changed but was expected not to", resource)
-	}
+	c.PlanAndShowStage(t, options).AssertResourcesThatShouldNotChange(t, resources)
 }
 
 // PlanWithResourcesExpectedToBeCreated plans the Terraform stage and checks that the specified resources are expected to be created.
@@ -240,9 +250,7 @@ func (c *StageClient) PlanWithSpecificResourcesThatShouldNotChange(t *testing.T,
 //   - options: The Terraform options.
 //   - resources: A list of resource addresses that are expected to be created.
 func (c *StageClient) PlanWithResourcesExpectedToBeCreated(t *testing.T, options *terraform.Options, resources []string) {
-	c.CheckResourcesChanges(t, options, resources, func(action tfjson.Actions) bool {
-		return action.Create()
-	}, "Resource %s was not marked to be created but was expected to")
+	c.PlanAndShowStage(t, options).AssertResourcesExpectedToBeCreated(t, resources)
 }
 
 // PlanWithResourcesExpectedToBeDeleted plans the Terraform stage and checks that the specified resources are expected to be deleted.
@@ -252,9 +260,7 @@ func (c *StageClient) PlanWithResourcesExpectedToBeCreated(t *testing.T, options
 //   - options: The Terraform options.
 //   - resources: A list of resource addresses that are expected to be deleted.
 func (c *StageClient) PlanWithResourcesExpectedToBeDeleted(t *testing.T, options *terraform.Options, resources []string) {
-	c.CheckResourcesChanges(t, options, resources, func(action tfjson.Actions) bool {
-		return action.Delete()
-	}, "Resource %s was not marked to be deleted but was expected to")
+	c.PlanAndShowStage(t, options).AssertResourcesExpectedToBeDeleted(t, resources)
 }
 
 // PlanWithResourcesExpectedToBeUpdated plans the Terraform stage and checks that the specified resources are expected to be updated.
@@ -264,9 +270,7 @@ func (c *StageClient) PlanWithResourcesExpectedToBeDeleted(t *testing.T, options
 //   - options: The Terraform options.
 //   - resources: A list of resource addresses that are expected to be updated.
 func (c *StageClient) PlanWithResourcesExpectedToBeUpdated(t *testing.T, options *terraform.Options, resources []string) {
-	c.CheckResourcesChanges(t, options, resources, func(action tfjson.Actions) bool {
-		return action.Update()
-	}, "Resource %s was not marked to be updated but was expected to")
+	c.PlanAndShowStage(t, options).AssertResourcesExpectedToBeUpdated(t, resources)
 }
 
 // PlanWithSpecificVariableValueToExpect plans the Terraform stage and checks that the specified variable has the expected value.
@@ -277,15 +281,7 @@ func (c *StageClient) PlanWithResourcesExpectedToBeUpdated(t *testing.T, options
 //   - variable: The name of the variable to check.
 //   - expectedValue: The expected value of the variable.
 func (c *StageClient) PlanWithSpecificVariableValueToExpect(t *testing.T, options *terraform.Options, variable, expectedValue string) {
-	out, err := terraform.InitAndPlanAndShowWithStructE(t, options)
-	require.NoErrorf(t, err, "Failed to plan terraform: %s", out)
-
-	variableFromPlan, found := out.RawPlan.Variables[variable]
-	require.Truef(t, found, "Variable %s was not found in the plan", variable)
-	require.NotNilf(t, variableFromPlan, "Variable %s was found in the plan but was nil", variable)
-
-	actualValue := variableFromPlan.Value
-	compareValues(t, actualValue, expectedValue, variable)
+	c.PlanAndShowStage(t, options).AssertVariableValue(t, variable, expectedValue)
 }
 
 // PlanAndAssertJSONWithJSONPath performs JSON path planning and assertion in Go testing.
@@ -295,28 +291,7 @@ func (c *StageClient) PlanWithSpecificVariableValueToExpect(t *testing.T, option
 //   - options: The Terraform options.
 //   - testCases: An array of JSON path test cases.
 func (c *StageClient) PlanAndAssertJSONWithJSONPath(t *testing.T, options *terraform.Options, testCases []JSONPathTestCases) {
-	jsonPlan := terraform.InitAndPlanAndShow(t, options)
-
-	for _, testCase := range testCases {
-		t.Run(testCase.TestName, func(t *testing.T) {
-			var result []interface{}
-			k8s.UnmarshalJSONPath(t, []byte(jsonPlan), testCase.JSONPathToCompare, &result)
-			assert.NotNil(t, result)
-
-			// if expected is slice then it's ok to compare entire slice
-			if reflect.TypeOf(testCase.ExpectedValue).Kind() == reflect.Slice {
-				assert.ObjectsAreEqual(testCase.ExpectedValue, result)
-			} else {
-				// work on the result[0]
-				t.Logf("result returned raw: %v", result)
-				v := result[0]
-				if !testCase.AllowDifferentType {
-					assert.Equal(t, reflect.TypeOf(testCase.ExpectedValue).Kind(), reflect.TypeOf(v).Kind())
-				}
-				applyTestType(t, testCase.TestType, v, testCase.ExpectedValue, fmt.Sprintf("JSONPATH query: %s", testCase.JSONPathToCompare))
-			}
-		})
-	}
+	c.PlanAndShowStage(t, options).AssertJSONWithJSONPath(t, testCases)
 }
 
 // applyTestType applies the specified test type to validate the actual value against the expected value.
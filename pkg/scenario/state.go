@@ -0,0 +1,271 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// State represents the state recorded by Terraform after apply, as returned by 'terraform show -json'.
+// It allows verifying what was actually recorded, such as computed ARNs or IDs.
+type State struct {
+	*tfjson.State
+
+	// JSON is the raw JSON representation of the state.
+	JSON string
+}
+
+// NewStateFromJSON creates a new State from the JSON representation of a Terraform state.
+//
+// Parameters:
+//   - jsonState: The JSON output of 'terraform show -json' without a plan file.
+//
+// Returns:
+//   - *State: The parsed state.
+//   - error: An error if the state could not be parsed.
+func NewStateFromJSON(jsonState string) (*State, error) {
+	state := &tfjson.State{}
+	if err := json.Unmarshal([]byte(jsonState), state); err != nil {
+		return nil, fmt.Errorf("failed to parse the terraform state: %v", err)
+	}
+
+	return &State{
+		State: state,
+		JSON:  jsonState,
+	}, nil
+}
+
+// StateStage reads the current state of the Terraform module using 'terraform show -json'.
+// It is meant to be run after the ApplyStage.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - *State: The state, parsed and ready to be queried.
+func (c *StageClient) StateStage(t *testing.T, options *terraform.Options) *State {
+	stateOptions, err := options.Clone()
+	require.NoErrorf(t, err, "Failed to clone the terraform options")
+
+	// Without a plan file, 'terraform show' returns the current state.
+	stateOptions.PlanFilePath = ""
+
+	out, err := terraform.ShowE(t, stateOptions)
+	require.NoErrorf(t, err, "Failed to show terraform state: %s", out)
+
+	state, err := NewStateFromJSON(out)
+	require.NoErrorf(t, err, "Failed to parse terraform state")
+
+	return state
+}
+
+// Resources returns every resource of the state, including the resources of the child modules.
+//
+// Returns:
+//   - []*tfjson.StateResource: The resources of the state.
+func (s *State) Resources() []*tfjson.StateResource {
+	var resources []*tfjson.StateResource
+
+	s.walkResources(func(_ string, resource *tfjson.StateResource) {
+		resources = append(resources, resource)
+	})
+
+	return resources
+}
+
+// ResourcesByType returns the resources of the state with the given type (e.g.: "aws_s3_bucket").
+//
+// Parameters:
+//   - resourceType: The resource type.
+//
+// Returns:
+//   - []*tfjson.StateResource: The resources with the given type.
+func (s *State) ResourcesByType(resourceType string) []*tfjson.StateResource {
+	var resources []*tfjson.StateResource
+
+	s.walkResources(func(_ string, resource *tfjson.StateResource) {
+		if resource.Type == resourceType {
+			resources = append(resources, resource)
+		}
+	})
+
+	return resources
+}
+
+// ResourcesByModule returns the resources that belong directly to the given module.
+//
+// Parameters:
+//   - moduleAddress: The module address (e.g.: "module.network"). Use an empty string for the root module.
+//
+// Returns:
+//   - []*tfjson.StateResource: The resources of the module.
+func (s *State) ResourcesByModule(moduleAddress string) []*tfjson.StateResource {
+	var resources []*tfjson.StateResource
+
+	s.walkResources(func(module string, resource *tfjson.StateResource) {
+		if module == moduleAddress {
+			resources = append(resources, resource)
+		}
+	})
+
+	return resources
+}
+
+// ResourcesMatching returns the resources whose address matches the given regular expression.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - pattern: The regular expression to match against the resource addresses.
+//
+// Returns:
+//   - []*tfjson.StateResource: The resources whose address matches.
+func (s *State) ResourcesMatching(t *testing.T, pattern string) []*tfjson.StateResource {
+	re, err := regexp.Compile(pattern)
+	require.NoErrorf(t, err, "Invalid regular expression %s", pattern)
+
+	var resources []*tfjson.StateResource
+
+	s.walkResources(func(_ string, resource *tfjson.StateResource) {
+		if re.MatchString(resource.Address) {
+			resources = append(resources, resource)
+		}
+	})
+
+	return resources
+}
+
+// Resource returns the resource with the given address, failing the test if it is not in the state.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address (e.g.: "module.network.aws_vpc.this").
+//
+// Returns:
+//   - *tfjson.StateResource: The resource.
+func (s *State) Resource(t *testing.T, address string) *tfjson.StateResource {
+	resource := s.findResource(address)
+	require.NotNilf(t, resource, "Resource %s was not found in the state", address)
+
+	return resource
+}
+
+// AssertResourceExists checks that the resource with the given address is in the state.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+func (s *State) AssertResourceExists(t *testing.T, address string) {
+	s.Resource(t, address)
+}
+
+// AssertResourceNotExists checks that the resource with the given address is not in the state.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+func (s *State) AssertResourceNotExists(t *testing.T, address string) {
+	require.Nilf(t, s.findResource(address), "Resource %s was found in the state but was expected not to", address)
+}
+
+// AssertResourceCountByType checks the number of resources of the given type in the state.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - resourceType: The resource type.
+//   - expected: The expected number of resources.
+func (s *State) AssertResourceCountByType(t *testing.T, resourceType string, expected int) {
+	require.Lenf(t, s.ResourcesByType(resourceType), expected, "Unexpected number of resources of type %s in the state", resourceType)
+}
+
+// AssertResourceAttribute checks the value of an attribute of the resource recorded in the state.
+// Nested blocks and lists are deeply compared, and numbers are compared regardless of their Go type.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path (e.g.: "versioning.0.enabled" or "versioning[0].enabled").
+//   - expected: The expected value.
+func (s *State) AssertResourceAttribute(t *testing.T, address, path string, expected interface{}) {
+	assertAttributeValue(t, s.attributeValues(t, address), address, path, expected, "state")
+}
+
+// AssertResourceAttributeMatches checks that an attribute of the resource recorded in the state matches the
+// given regular expression (e.g.: "^arn:aws:s3:::").
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+//   - pattern: The regular expression to match.
+func (s *State) AssertResourceAttributeMatches(t *testing.T, address, path, pattern string) {
+	actual, found := lookupAttribute(s.attributeValues(t, address), path)
+	require.Truef(t, found, "Attribute %s was not found in the state of resource %s", path, address)
+
+	re, err := regexp.Compile(pattern)
+	require.NoErrorf(t, err, "Invalid regular expression %s", pattern)
+
+	require.Truef(t, re.MatchString(fmt.Sprintf("%v", actual)),
+		"Attribute %s of resource %s with value %v does not match the regular expression %s", path, address, actual, pattern)
+}
+
+// AssertResourceAttributeNotEmpty checks that an attribute of the resource was recorded in the state with a
+// non-empty value, which is useful for computed attributes such as IDs or ARNs.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - address: The resource address.
+//   - path: The attribute path.
+func (s *State) AssertResourceAttributeNotEmpty(t *testing.T, address, path string) {
+	actual, found := lookupAttribute(s.attributeValues(t, address), path)
+	require.Truef(t, found, "Attribute %s was not found in the state of resource %s", path, address)
+	require.NotEmptyf(t, actual, "Attribute %s of resource %s is empty", path, address)
+}
+
+// attributeValues returns the attribute values recorded in the state for the resource.
+func (s *State) attributeValues(t *testing.T, address string) interface{} {
+	values := s.Resource(t, address).AttributeValues
+	if values == nil {
+		return nil
+	}
+
+	return values
+}
+
+// findResource returns the resource with the given address, or nil if it is not in the state.
+func (s *State) findResource(address string) *tfjson.StateResource {
+	var found *tfjson.StateResource
+
+	s.walkResources(func(_ string, resource *tfjson.StateResource) {
+		if resource.Address == address {
+			found = resource
+		}
+	})
+
+	return found
+}
+
+// walkResources calls fn for every resource of the state, along with the address of the module it belongs to.
+func (s *State) walkResources(fn func(moduleAddress string, resource *tfjson.StateResource)) {
+	if s.State == nil || s.Values == nil || s.Values.RootModule == nil {
+		return
+	}
+
+	walkStateModule(s.Values.RootModule, fn)
+}
+
+// walkStateModule calls fn for every resource of the module and its child modules.
+func walkStateModule(module *tfjson.StateModule, fn func(moduleAddress string, resource *tfjson.StateResource)) {
+	for _, resource := range module.Resources {
+		fn(module.Address, resource)
+	}
+
+	for _, child := range module.ChildModules {
+		walkStateModule(child, fn)
+	}
+}
@@ -0,0 +1,72 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStateJSON = `{
+  "format_version": "1.0",
+  "terraform_version": "1.7.5",
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "random_id.this",
+          "mode": "managed",
+          "type": "random_id",
+          "name": "this",
+          "provider_name": "registry.terraform.io/hashicorp/random",
+          "schema_version": 0,
+          "values": {"byte_length": 8, "hex": "a1b2c3d4e5f60718", "keepers": null}
+        }
+      ],
+      "child_modules": [
+        {
+          "address": "module.storage",
+          "resources": [
+            {
+              "address": "module.storage.aws_s3_bucket.this",
+              "mode": "managed",
+              "type": "aws_s3_bucket",
+              "name": "this",
+              "provider_name": "registry.terraform.io/hashicorp/aws",
+              "schema_version": 0,
+              "values": {"arn": "arn:aws:s3:::my-bucket", "versioning": [{"enabled": true}]}
+            }
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+func TestStateQueries(t *testing.T) {
+	state, err := NewStateFromJSON(testStateJSON)
+	require.NoError(t, err)
+
+	assert.Len(t, state.Resources(), 2)
+	assert.Len(t, state.ResourcesByType("aws_s3_bucket"), 1)
+	assert.Len(t, state.ResourcesByModule(""), 1)
+	assert.Len(t, state.ResourcesByModule("module.storage"), 1)
+	assert.Len(t, state.ResourcesMatching(t, `^module\.storage\.`), 1)
+	assert.Equal(t, "aws_s3_bucket", state.Resource(t, "module.storage.aws_s3_bucket.this").Type)
+
+	state.AssertResourceExists(t, "random_id.this")
+	state.AssertResourceNotExists(t, "random_id.other")
+	state.AssertResourceCountByType(t, "random_id", 1)
+	state.AssertResourceAttribute(t, "random_id.this", "byte_length", 8)
+	state.AssertResourceAttribute(t, "module.storage.aws_s3_bucket.this", "versioning[0].enabled", true)
+	state.AssertResourceAttributeMatches(t, "module.storage.aws_s3_bucket.this", "arn", `^arn:aws:s3:::`)
+	state.AssertResourceAttributeNotEmpty(t, "random_id.this", "hex")
+}
+
+func TestEmptyState(t *testing.T) {
+	state, err := NewStateFromJSON(`{"format_version": "1.0"}`)
+	require.NoError(t, err)
+
+	assert.Empty(t, state.Resources())
+	state.AssertResourceNotExists(t, "random_id.this")
+}
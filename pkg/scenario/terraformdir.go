@@ -1,6 +1,7 @@
 package scenario
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/Excoriate/tftest/pkg/validation"
@@ -36,3 +37,38 @@ func GetTerraformDir(t *testing.T, path string, isParallel bool) (string, error)
 
 	return path, nil
 }
+
+// GetTerragruntDir returns the Terragrunt directory path for the scenario.
+// The path can be either a single Terragrunt module (a directory with a terragrunt.hcl file) or
+// a Terragrunt stack (a directory whose subdirectories are Terragrunt units).
+// If the scenario is running in parallel, it sets up the directory for parallelism.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - path: The path to the Terragrunt module or stack directory.
+//   - isParallel: A boolean flag indicating whether the scenario is running in parallel.
+//
+// Returns:
+//   - string: The path to the Terragrunt directory (or a temporary directory if running in parallel).
+//   - error: An error if the Terragrunt directory is not valid or if parallel setup fails.
+//
+// Example:
+//
+//	terragruntDir, err := GetTerragruntDir(t, "/path/to/terragrunt/stack", false)
+//	if err != nil {
+//	    t.Fatalf("Error getting Terragrunt directory: %v", err)
+//	}
+//	fmt.Printf("Terragrunt directory: %s\n", terragruntDir)
+func GetTerragruntDir(t *testing.T, path string, isParallel bool) (string, error) {
+	if moduleErr := validation.IsATerragruntModule(path); moduleErr != nil {
+		if stackErr := validation.IsATerragruntStack(path); stackErr != nil {
+			return "", fmt.Errorf("the path %s is neither a terragrunt module nor a terragrunt stack: %v", path, stackErr)
+		}
+	}
+
+	if isParallel {
+		return SetupTerraformDirForParallelism(t, path)
+	}
+
+	return path, nil
+}
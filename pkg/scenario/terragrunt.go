@@ -0,0 +1,134 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/tfdir"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// requireTerragrunt fails the test if the options are not configured to run Terragrunt.
+func requireTerragrunt(t *testing.T, options *terraform.Options) {
+	require.Equalf(t, TerragruntBinary, options.TerraformBinary,
+		"The scenario is not configured to run terragrunt, use WithTerragrunt() or NewTerragrunt() to enable it")
+}
+
+// TerragruntUnitOptions returns a copy of the Terraform options targeting a single unit of a Terragrunt stack.
+// It allows running any of the StageClient stages against a specific unit of the stack.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the stack.
+//   - unit: The unit directory, relative to the stack directory.
+//
+// Returns:
+//   - *terraform.Options: The Terraform options for the unit.
+func (c *StageClient) TerragruntUnitOptions(t *testing.T, options *terraform.Options, unit string) *terraform.Options {
+	requireTerragrunt(t, options)
+
+	unitOptions, err := options.Clone()
+	require.NoErrorf(t, err, "Failed to clone the terraform options for unit %s", unit)
+
+	unitOptions.TerraformDir = filepath.Join(options.TerraformDir, unit)
+
+	return unitOptions
+}
+
+// TerragruntRunAllPlanStage plans every unit of the Terragrunt stack using 'terragrunt run-all plan'.
+// The plan of each unit is saved in its own Terragrunt cache directory.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+func (c *StageClient) TerragruntRunAllPlanStage(t *testing.T, options *terraform.Options) {
+	requireTerragrunt(t, options)
+
+	planOptions, err := options.Clone()
+	require.NoErrorf(t, err, "Failed to clone the terraform options")
+
+	planOptions.PlanFilePath = DefaultPlanOutput
+
+	out, err := terraform.RunTerraformCommandE(t, planOptions,
+		terraform.FormatArgs(planOptions, "run-all", "plan", "-input=false", "-lock=false")...)
+	require.NoErrorf(t, err, "Failed to plan terragrunt stack: %s", out)
+}
+
+// TerragruntRunAllPlanAndShowStage plans every unit of the Terragrunt stack once and returns the Plan of each unit,
+// so that all the plan assertions can be run against every unit without planning again.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - map[string]*Plan: The plans, keyed by the unit directory relative to the stack.
+func (c *StageClient) TerragruntRunAllPlanAndShowStage(t *testing.T, options *terraform.Options) map[string]*Plan {
+	c.TerragruntRunAllPlanStage(t, options)
+
+	units, err := tfdir.GetTerragruntUnits(options.TerraformDir)
+	require.NoErrorf(t, err, "Failed to get the terragrunt units of the stack %s", options.TerraformDir)
+
+	plans := make(map[string]*Plan, len(units))
+
+	for _, unit := range units {
+		unitOptions := c.TerragruntUnitOptions(t, options, unit)
+		unitOptions.PlanFilePath = DefaultPlanOutput
+
+		jsonPlan, showErr := terraform.ShowE(t, unitOptions)
+		require.NoErrorf(t, showErr, "Failed to show the plan of terragrunt unit %s: %s", unit, jsonPlan)
+
+		plan, parseErr := NewPlanFromJSON(jsonPlan)
+		require.NoErrorf(t, parseErr, "Failed to parse the plan of terragrunt unit %s", unit)
+
+		plans[unit] = plan
+	}
+
+	return plans
+}
+
+// TerragruntRunAllPlanWithStruct plans every unit of the Terragrunt stack and parses the plan of each unit.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+//
+// Returns:
+//   - map[string]*terraform.PlanStruct: The parsed plans, keyed by the unit directory relative to the stack.
+func (c *StageClient) TerragruntRunAllPlanWithStruct(t *testing.T, options *terraform.Options) map[string]*terraform.PlanStruct {
+	plans := c.TerragruntRunAllPlanAndShowStage(t, options)
+
+	planStructs := make(map[string]*terraform.PlanStruct, len(plans))
+	for unit, plan := range plans {
+		planStructs[unit] = plan.PlanStruct
+	}
+
+	return planStructs
+}
+
+// TerragruntRunAllApplyStage applies every unit of the Terragrunt stack using 'terragrunt run-all apply'.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+func (c *StageClient) TerragruntRunAllApplyStage(t *testing.T, options *terraform.Options) {
+	requireTerragrunt(t, options)
+	c.requireAllowedAWSTarget(t, options)
+
+	out, err := terraform.TgApplyAllE(t, options)
+	require.NoErrorf(t, err, "Failed to apply terragrunt stack: %s", out)
+}
+
+// TerragruntRunAllDestroyStage destroys every unit of the Terragrunt stack using 'terragrunt run-all destroy'.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options.
+func (c *StageClient) TerragruntRunAllDestroyStage(t *testing.T, options *terraform.Options) {
+	requireTerragrunt(t, options)
+	c.requireAllowedAWSTarget(t, options)
+
+	out, err := terraform.TgDestroyAllE(t, options)
+	require.NoErrorf(t, err, "Failed to destroy terragrunt stack: %s", out)
+}
@@ -0,0 +1,149 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Excoriate/tftest/pkg/gh"
+	"github.com/Excoriate/tftest/pkg/git_tools"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultStateFile is the name of the state file written by the local backend.
+const defaultStateFile = "terraform.tfstate"
+
+// UpgradeStage applies the module as it was in the latest GitHub release of the repository, then plans the
+// current code against the resulting state and checks that no resource is destroyed or replaced.
+// See UpgradeFromRefStage for the details.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the current code.
+//   - repoURL: The URL of the GitHub repository (e.g., "https://github.com/owner/repo").
+//   - moduleDir: The directory of the module in the current checkout.
+//
+// Returns:
+//   - *Plan: The plan of the current code against the state of the latest release.
+func (c *StageClient) UpgradeStage(t *testing.T, options *terraform.Options, repoURL, moduleDir string) *Plan {
+	tag, err := gh.FetchLatestReleaseTag(repoURL)
+	require.NoErrorf(t, err, "Failed to fetch the latest release of %s", repoURL)
+
+	return c.UpgradeFromRefStage(t, options, tag, moduleDir)
+}
+
+// UpgradeFromRefStage checks out the given ref (usually the previous release tag) into a temporary worktree,
+// applies the module from there, moves the resulting state into the working directory of the current code, and
+// plans it. The plan must not destroy or replace any resource.
+//
+// The checkout gets the same override files (backend, providers) and workspace as the scenario. The module is
+// expected to use the local backend, so that the state can be carried from one checkout to the other; the state
+// file is resolved from the backend forced with WithLocalBackend and the workspace, if any. If the apply of the
+// given ref fails, what it created is destroyed. Otherwise resources are left in place: destroy them with the same
+// options once the test is done.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the current code.
+//   - ref: The Git ref (tag, branch or commit) to upgrade from.
+//   - moduleDir: The directory of the module in the current checkout.
+//
+// Returns:
+//   - *Plan: The plan of the current code against the state of the given ref.
+//
+// Example:
+//
+//	defer s.Stg.DestroyStage(t, s.GetTerraformOptions())
+//	plan := s.Stg.UpgradeFromRefStage(t, s.GetTerraformOptions(), "v1.2.0", "../../data/tf-random")
+//	plan.AssertResourcesExpectedToBeUpdated(t, []string{"random_id.this"})
+func (c *StageClient) UpgradeFromRefStage(t *testing.T, options *terraform.Options, ref, moduleDir string) *Plan {
+	absModuleDir, err := filepath.Abs(moduleDir)
+	require.NoErrorf(t, err, "Failed to resolve the module directory %s", moduleDir)
+
+	repoRoot, err := git_tools.FindGitRepoRootUsingGit(absModuleDir)
+	require.NoErrorf(t, err, "Failed to find the Git repository of %s", absModuleDir)
+
+	relModuleDir, err := filepath.Rel(repoRoot, absModuleDir)
+	require.NoErrorf(t, err, "Failed to resolve %s relative to the repository root %s", absModuleDir, repoRoot)
+
+	worktree := filepath.Join(t.TempDir(), strings.ReplaceAll(ref, "/", "-"))
+	require.NoErrorf(t, git_tools.AddWorktree(repoRoot, ref, worktree), "Failed to check out %s", ref)
+
+	t.Cleanup(func() {
+		if err := git_tools.RemoveWorktree(repoRoot, worktree); err != nil {
+			t.Logf("Failed to remove the worktree of %s: %v", ref, err)
+		}
+	})
+
+	c.requireAllowedAWSTarget(t, options)
+
+	previousOptions, err := options.Clone()
+	require.NoErrorf(t, err, "Failed to clone the terraform options")
+
+	previousOptions.TerraformDir = filepath.Join(worktree, relModuleDir)
+	previousOptions.PlanFilePath = ""
+
+	// The previous release must run against the same backend and providers as the current code.
+	names := make([]string, 0, len(c.generatedFiles))
+	for name := range c.generatedFiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		require.NoErrorf(t, writeOverrideFile(t, previousOptions.TerraformDir, c.generatedFiles[name]),
+			"Failed to inject the override file %s into the checkout of %s", name, ref)
+	}
+
+	workspace := previousOptions.EnvVars[WorkspaceEnvVar]
+	if workspace != "" && workspace != DefaultWorkspace {
+		require.NoErrorf(t, ensureWorkspace(t, previousOptions, workspace),
+			"Failed to create the workspace %s in the checkout of %s", workspace, ref)
+	}
+
+	diagnostics, out, err := applyWithDiagnostics(t, previousOptions)
+	if err != nil {
+		// Nothing points to these resources yet, so they have to be removed from the previous checkout.
+		destroyOut, destroyErr := terraform.DestroyE(t, previousOptions)
+		assert.NoErrorf(t, destroyErr, "Failed to destroy what the failed apply at %s created, remove it by hand: %s", ref, destroyOut)
+	}
+
+	requireNoDiagnosticErrors(t, "apply", diagnostics, out, err)
+
+	backendConfig := c.localBackendConfig(t)
+	previousState := localStatePath(previousOptions.TerraformDir, backendConfig, workspace)
+	currentState := localStatePath(options.TerraformDir, backendConfig, workspace)
+
+	if previousState != currentState {
+		state, err := os.ReadFile(previousState)
+		require.NoErrorf(t, err, "Failed to read the state applied at %s, is the module using the local backend?", ref)
+
+		require.NoErrorf(t, os.MkdirAll(filepath.Dir(currentState), 0o700), "Failed to create the directory of %s", currentState)
+
+		err = os.WriteFile(currentState, state, 0o600)
+		require.NoErrorf(t, err, "Failed to copy the state applied at %s into %s", ref, currentState)
+	}
+
+	plan := c.PlanAndShowStage(t, options)
+	plan.AssertNoDestroyOrReplace(t)
+
+	return plan
+}
+
+// localBackendConfig returns the arguments of the local backend forced on the scenario, if any. The upgrade stages
+// carry the state from one checkout to the other, so they cannot work with another backend.
+func (c *StageClient) localBackendConfig(t *testing.T) map[string]interface{} {
+	if c.backend == nil {
+		return nil
+	}
+
+	require.Equalf(t, LocalBackend, c.backend.backendType,
+		"The state cannot be carried from one checkout to the other with the %s backend, use WithLocalBackend", c.backend.backendType)
+
+	return c.backend.config
+}
@@ -0,0 +1,235 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// backendStateFile is the file of the data directory that records the backend configuration of the working
+// directory.
+const backendStateFile = "terraform.tfstate"
+
+// WithWorkspace makes every stage of the scenario run in the given Terraform workspace. The workspace is created
+// (or reused) when the scenario is built, and deleted once the test finishes. Destroy the resources before that,
+// since a workspace that still has resources is not deleted.
+//
+// The workspace is selected through the TF_WORKSPACE environment variable, so scenarios running in parallel
+// can share the same working directory with a workspace each.
+//
+// Parameters:
+//   - name: The name of the workspace.
+//
+// Returns:
+//   - OptFn: A function to modify the options.
+func WithWorkspace(name string) OptFn {
+	return func(o *Options) error {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("the workspace name cannot be empty")
+		}
+
+		o.workspace = name
+
+		return nil
+	}
+}
+
+// GetWorkspace returns the Terraform workspace of the scenario.
+//
+// Returns:
+//   - string: The name of the workspace, "default" if none was set.
+func (c *Client) GetWorkspace() string {
+	if c.workspace == "" {
+		return DefaultWorkspace
+	}
+
+	return c.workspace
+}
+
+// setupWorkspace creates the workspace (or reuses it if it exists) and selects it for the given options through
+// the TF_WORKSPACE environment variable only: the workspace recorded in the working directory, which may be shared
+// with other scenarios, is never changed. The workspace is deleted when the test finishes.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the scenario.
+//   - name: The name of the workspace.
+//
+// Returns:
+//   - error: An error if the workspace could not be created.
+func setupWorkspace(t *testing.T, options *terraform.Options, name string) error {
+	options.EnvVars[WorkspaceEnvVar] = name
+
+	if name == DefaultWorkspace {
+		return nil
+	}
+
+	if err := ensureWorkspace(t, options, name); err != nil {
+		return err
+	}
+
+	defaultOptions, err := defaultWorkspaceOptions(options)
+	if err != nil {
+		return err
+	}
+
+	t.Cleanup(func() {
+		if out, err := terraform.RunTerraformCommandE(t, defaultOptions, "workspace", "delete", name); err != nil {
+			t.Logf("Failed to delete the workspace %s (is it empty?): %v: %s", name, err, out)
+		}
+	})
+
+	return nil
+}
+
+// ensureWorkspace initializes the working directory and creates the workspace, unless it exists. Nothing is
+// selected in the working directory.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the scenario.
+//   - name: The name of the workspace.
+//
+// Returns:
+//   - error: An error if the workspace could not be created.
+func ensureWorkspace(t *testing.T, options *terraform.Options, name string) error {
+	defaultOptions, err := defaultWorkspaceOptions(options)
+	if err != nil {
+		return err
+	}
+
+	if out, err := terraform.InitE(t, defaultOptions); err != nil {
+		return fmt.Errorf("failed to init terraform before creating the workspace %s: %v: %s", name, err, out)
+	}
+
+	out, err := terraform.RunTerraformCommandE(t, defaultOptions, "workspace", "list")
+	if err != nil {
+		return fmt.Errorf("failed to list the workspaces: %v: %s", err, out)
+	}
+
+	if isListedWorkspace(out, name) {
+		return nil
+	}
+
+	return createWorkspace(t, options, name)
+}
+
+// defaultWorkspaceOptions returns a copy of the options that runs in the default workspace. It always exists, so
+// the other workspaces are managed from it.
+func defaultWorkspaceOptions(options *terraform.Options) (*terraform.Options, error) {
+	defaultOptions, err := options.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone the terraform options: %v", err)
+	}
+
+	defaultOptions.EnvVars[WorkspaceEnvVar] = DefaultWorkspace
+
+	return defaultOptions, nil
+}
+
+// createWorkspace creates the workspace. 'terraform workspace new' also selects the workspace it creates, by
+// recording it in the data directory (.terraform), so it runs with a throwaway data directory that only holds the
+// backend configuration of the working directory.
+//
+// Parameters:
+//   - t: The testing instance.
+//   - options: The Terraform options of the scenario.
+//   - name: The name of the workspace.
+//
+// Returns:
+//   - error: An error if the workspace could not be created.
+func createWorkspace(t *testing.T, options *terraform.Options, name string) error {
+	dataDir := t.TempDir()
+
+	backendState, err := os.ReadFile(filepath.Join(terraformDataDir(options), backendStateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read the backend configuration of %s: %v", options.TerraformDir, err)
+	}
+
+	if err == nil {
+		if err := os.WriteFile(filepath.Join(dataDir, backendStateFile), backendState, 0o600); err != nil {
+			return fmt.Errorf("failed to copy the backend configuration of %s: %v", options.TerraformDir, err)
+		}
+	}
+
+	newOptions, err := options.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to clone the terraform options: %v", err)
+	}
+
+	newOptions.EnvVars[DataDirEnvVar] = dataDir
+	newOptions.EnvVars[WorkspaceEnvVar] = name
+
+	if out, err := terraform.RunTerraformCommandE(t, newOptions, "workspace", "new", name); err != nil {
+		return fmt.Errorf("failed to create the workspace %s: %v: %s", name, err, out)
+	}
+
+	return nil
+}
+
+// terraformDataDir returns the data directory of the working directory: TF_DATA_DIR if set, .terraform otherwise.
+func terraformDataDir(options *terraform.Options) string {
+	dataDir := options.EnvVars[DataDirEnvVar]
+	if dataDir == "" {
+		dataDir = os.Getenv(DataDirEnvVar)
+	}
+
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+
+	return resolvePath(options.TerraformDir, dataDir)
+}
+
+// localStatePath returns the path of the state file written by the local backend, following the same rules as
+// Terraform: the "path" argument (or terraform.tfstate) for the default workspace, and
+// "<workspace_dir>/<workspace>/terraform.tfstate" for the others.
+//
+// Parameters:
+//   - tfDir: The working directory of the scenario.
+//   - config: The arguments of the local backend, nil for its defaults.
+//   - workspace: The workspace of the scenario.
+//
+// Returns:
+//   - string: The path of the state file.
+func localStatePath(tfDir string, config map[string]interface{}, workspace string) string {
+	if workspace != "" && workspace != DefaultWorkspace {
+		workspaceDir := "terraform.tfstate.d"
+		if dir, ok := config["workspace_dir"].(string); ok && dir != "" {
+			workspaceDir = dir
+		}
+
+		return resolvePath(tfDir, filepath.Join(workspaceDir, workspace, defaultStateFile))
+	}
+
+	if path, ok := config["path"].(string); ok && path != "" {
+		return resolvePath(tfDir, path)
+	}
+
+	return filepath.Join(tfDir, defaultStateFile)
+}
+
+// resolvePath resolves a path relative to the given directory, unless it is absolute.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// isListedWorkspace reports whether the workspace is in the output of 'terraform workspace list', where the
+// selected workspace is marked with an asterisk.
+func isListedWorkspace(output, name string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*")) == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsListedWorkspace(t *testing.T) {
+	output := "  default\n* staging\n  tftest-example\n"
+
+	assert.True(t, isListedWorkspace(output, "staging"))
+	assert.True(t, isListedWorkspace(output, "tftest-example"))
+	assert.False(t, isListedWorkspace(output, "tftest"))
+	assert.False(t, isListedWorkspace("", "staging"))
+}
+
+func TestTerraformDataDir(t *testing.T) {
+	t.Setenv(DataDirEnvVar, "")
+
+	dir := t.TempDir()
+
+	assert.Equal(t, filepath.Join(dir, ".terraform"), terraformDataDir(&terraform.Options{TerraformDir: dir}))
+	assert.Equal(t, filepath.Join(dir, "data"), terraformDataDir(&terraform.Options{
+		TerraformDir: dir,
+		EnvVars:      map[string]string{DataDirEnvVar: "data"},
+	}))
+	assert.Equal(t, "/tmp/tf-data", terraformDataDir(&terraform.Options{
+		TerraformDir: dir,
+		EnvVars:      map[string]string{DataDirEnvVar: "/tmp/tf-data"},
+	}))
+}
@@ -0,0 +1,78 @@
+package tfdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TerragruntConfigFile is the name of the file that turns a directory into a Terragrunt unit.
+const TerragruntConfigFile = "terragrunt.hcl"
+
+// GetTerragruntUnits returns the directories (relative to the stack directory) that contain a terragrunt.hcl file.
+// The stack directory itself is not considered a unit, since it usually holds the root configuration that the
+// units include. Hidden directories (e.g.: .terragrunt-cache, .terraform) are skipped.
+//
+// Parameters:
+//   - stackDir: The path to the Terragrunt stack directory. This parameter is required.
+//
+// Returns:
+//   - []string: The sorted list of unit directories, relative to the stack directory.
+//   - error: An error if the stack directory could not be traversed.
+//
+// Example:
+//
+//	units, err := GetTerragruntUnits("/path/to/terragrunt/stack")
+//	if err != nil {
+//	    log.Fatalf("Error getting Terragrunt units: %v", err)
+//	}
+//	fmt.Printf("Terragrunt units: %v\n", units)
+func GetTerragruntUnits(stackDir string) ([]string, error) {
+	if stackDir == "" {
+		return nil, fmt.Errorf("stackDir is required")
+	}
+
+	var units []string
+
+	err := filepath.Walk(stackDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != stackDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.Name() != TerragruntConfigFile {
+			return nil
+		}
+
+		unitDir := filepath.Dir(path)
+		if unitDir == filepath.Clean(stackDir) {
+			return nil
+		}
+
+		relPath, relErr := filepath.Rel(stackDir, unitDir)
+		if relErr != nil {
+			return fmt.Errorf("failed to get relative path for unit %s: %v", unitDir, relErr)
+		}
+
+		units = append(units, relPath)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan the terragrunt stack %s: %v", stackDir, err)
+	}
+
+	sort.Strings(units)
+
+	return units, nil
+}
@@ -0,0 +1,41 @@
+package tfdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTerragruntUnits(t *testing.T) {
+	stackDir := t.TempDir()
+
+	files := []string{
+		"terragrunt.hcl",
+		"network/terragrunt.hcl",
+		"app/api/terragrunt.hcl",
+		"app/api/.terragrunt-cache/abc/terragrunt.hcl",
+		"docs/README.md",
+	}
+
+	for _, f := range files {
+		path := filepath.Join(stackDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# test"), 0o600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	units, err := GetTerragruntUnits(stackDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("app", "api"), "network"}, units)
+
+	_, err = GetTerragruntUnits("")
+	assert.Error(t, err)
+
+	_, err = GetTerragruntUnits(filepath.Join(stackDir, "nonexistent"))
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/Excoriate/tftest/pkg/tfdir"
 	"github.com/Excoriate/tftest/pkg/utils"
 )
 
@@ -34,3 +35,37 @@ func IsATerragruntModule(path string) error {
 
 	return nil
 }
+
+// IsATerragruntStack checks if the given path is a valid Terragrunt stack.
+// A valid Terragrunt stack is a directory that contains at least one unit (a subdirectory with a terragrunt.hcl file).
+//
+// Parameters:
+//   - path: The path to the directory to check.
+//
+// Returns:
+//   - error: An error if the path is not a valid Terragrunt stack.
+//
+// Example:
+//
+//	err := IsATerragruntStack("/path/to/stack")
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	} else {
+//	    fmt.Println("The path is a valid Terragrunt stack.")
+//	}
+func IsATerragruntStack(path string) error {
+	if err := utils.IsValidDirE(path); err != nil {
+		return fmt.Errorf("the terragrunt stack does not exist: %s", path)
+	}
+
+	units, err := tfdir.GetTerragruntUnits(path)
+	if err != nil {
+		return err
+	}
+
+	if len(units) == 0 {
+		return fmt.Errorf("the terragrunt stack does not have any terragrunt units: %s", path)
+	}
+
+	return nil
+}
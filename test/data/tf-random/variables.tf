@@ -22,6 +22,11 @@ variable "random_length_string" {
   type        = number
   description = "The length of the random string to generate."
   default     = 8
+
+  validation {
+    condition     = var.random_length_string >= 4
+    error_message = "The random string must be at least 4 characters long."
+  }
 }
 
 variable "random_special_characters" {
@@ -0,0 +1,17 @@
+package simple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Excoriate/tftest/pkg/scenario"
+)
+
+func TestStaticChecks(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel())
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	s.Stg.FmtCheckStage(t, s.GetTerraformOptions())
+	s.Stg.ValidateStage(t, s.GetTerraformOptions()).AssertNoWarnings(t)
+}
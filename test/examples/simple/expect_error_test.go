@@ -0,0 +1,22 @@
+package simple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Excoriate/tftest/pkg/scenario"
+)
+
+func TestVariableValidation(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel(), scenario.WithVars(map[string]interface{}{
+		"random_length_string": 2,
+	}))
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	s.Stg.PlanExpectError(t, s.GetTerraformOptions(), scenario.ExpectedError{
+		Summary:  "Invalid value for variable",
+		Detail:   "must be at least 4 characters long",
+		Variable: "random_length_string",
+	})
+}
@@ -0,0 +1,49 @@
+package simple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Excoriate/tftest/pkg/scenario"
+)
+
+func TestOutputsAfterApply(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel())
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	defer s.Stg.DestroyStage(t, s.GetTerraformOptions())
+	s.Stg.ApplyStage(t, s.GetTerraformOptions())
+
+	outputs := s.Stg.OutputStage(t, s.GetTerraformOptions())
+
+	tags := scenario.OutputAs[map[string]string](t, outputs, "tags_set")
+	assert.Empty(t, tags)
+
+	outputs.AssertEqual(t, "is_enabled", true)
+	outputs.AssertSensitive(t, "random_password")
+	outputs.AssertMatches(t, "random_uuid", `"result":"[0-9a-f-]{36}"`)
+}
+
+func TestStateAfterApply(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel())
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	defer s.Stg.DestroyStage(t, s.GetTerraformOptions())
+	s.Stg.ApplyStage(t, s.GetTerraformOptions())
+
+	state := s.Stg.StateStage(t, s.GetTerraformOptions())
+
+	assert.Len(t, state.ResourcesMatching(t, `^random_`), 4)
+	state.AssertResourceAttribute(t, "random_id.this", "byte_length", 8)
+	state.AssertResourceAttributeNotEmpty(t, "random_uuid.this", "result")
+	state.AssertResourceAttributeMatches(t, "random_uuid.this", "id", `^[0-9a-f-]{36}$`)
+}
+
+func TestIdempotency(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel())
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	defer s.Stg.DestroyStage(t, s.GetTerraformOptions())
+	s.Stg.IdempotencyStage(t, s.GetTerraformOptions())
+}
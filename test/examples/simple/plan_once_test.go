@@ -0,0 +1,39 @@
+package simple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Excoriate/tftest/pkg/scenario"
+)
+
+func TestPlanOnceAssertMany(t *testing.T) {
+	s, err := scenario.New(t, "../../data/tf-random")
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	plan := s.Stg.PlanAndShowStage(t, s.GetTerraformOptions())
+
+	plan.AssertAnySortOfChanges(t)
+	plan.AssertExpectedChanges(t, 4)
+	plan.AssertResourcesExpectedToBeCreated(t, []string{"random_id.this", "random_uuid.this"})
+	plan.AssertVariableValue(t, "random_length_password", "16")
+}
+
+func TestPlanSnapshot(t *testing.T) {
+	s, err := scenario.New(t, "../../data/tf-random")
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	s.Stg.PlanStageMatchesSnapshot(t, s.GetTerraformOptions(), scenario.SnapshotPath("tf-random"))
+}
+
+func TestPlanResourceAttributes(t *testing.T) {
+	s, err := scenario.New(t, "../../data/tf-random")
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	plan := s.Stg.PlanAndShowStage(t, s.GetTerraformOptions())
+
+	plan.AssertResourceAttributeAfter(t, "random_password.this", "length", 16)
+	plan.AssertResourceAttributeUnknown(t, "random_password.this", "result")
+	plan.AssertResourceAttributeSensitive(t, "random_password.this", "result")
+}
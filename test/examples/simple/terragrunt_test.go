@@ -0,0 +1,28 @@
+package simple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Excoriate/tftest/pkg/scenario"
+)
+
+func TestTerragruntPlanScenario(t *testing.T) {
+	s, err := scenario.NewTerragrunt(t, "../../data/tg-random")
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	s.Stg.PlanStage(t, s.GetTerraformOptions())
+}
+
+func TestTerragruntRunAllPlanScenario(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tg-stack", scenario.WithTerragrunt())
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	plans := s.Stg.TerragruntRunAllPlanWithStruct(t, s.GetTerraformOptions())
+	assert.Len(t, plans, 2)
+
+	for unit, plan := range plans {
+		assert.Containsf(t, plan.ResourceChangesMap, "random_id.this", "Unit %s is expected to create random_id.this", unit)
+	}
+}
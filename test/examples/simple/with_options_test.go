@@ -61,3 +61,45 @@ func TestWithScannedTFVars(t *testing.T) {
 
 	s.Stg.PlanStage(t, s.GetTerraformOptions())
 }
+
+func TestWithWorkspaceInvalid(t *testing.T) {
+	_, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithWorkspace(" "))
+
+	assert.Errorf(t, err, "It was expected to fail with an error due to the empty workspace name")
+}
+
+func TestWithWorkspace(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random",
+		scenario.WithParallel(), scenario.WithWorkspace("tftest-example"))
+
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+	assert.Equal(t, "tftest-example", s.GetWorkspace())
+
+	s.Stg.PlanStageWithAnySortOfChanges(t, s.GetTerraformOptions())
+}
+
+func TestWithLocalBackend(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random",
+		scenario.WithParallel(), scenario.WithLocalBackend())
+
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	defer s.Stg.DestroyStage(t, s.GetTerraformOptions())
+	s.Stg.ApplyStage(t, s.GetTerraformOptions())
+
+	assert.FileExists(t, s.GetStateFilePath())
+}
+
+func TestWithOverrideFile(t *testing.T) {
+	s, err := scenario.NewWithOptions(t, "../../data/tf-random", scenario.WithParallel(),
+		scenario.WithOverrideFile("random_override.tf", `
+resource "random_id" "this" {
+  byte_length = 16
+}
+`))
+
+	assert.NoErrorf(t, err, "Failed to create scenario: %s", err)
+
+	plan := s.Stg.PlanAndShowStage(t, s.GetTerraformOptions())
+	plan.AssertResourceAttributeAfter(t, "random_id.this", "byte_length", 16)
+}